@@ -1,12 +1,11 @@
 package sqlstatement
 
 import (
+	"errors"
 	"fmt"
 	"github.com/samber/lo"
-	"github.com/tianlin0/go-plat-utils/cond"
-	"github.com/tianlin0/go-plat-utils/conv"
-	"github.com/tianlin0/go-plat-utils/utils"
-	"reflect"
+	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -17,21 +16,49 @@ type Condition struct {
 	Value    any
 }
 
+// Range 表示 BETWEEN / NOT BETWEEN 的取值区间
+type Range struct {
+	Start any
+	End   any
+}
+
 // LogicCondition 表示逻辑分组
 type LogicCondition struct {
-	Conditions []any  // 可以是 Condition 或 LogicCondition
+	Conditions []any  // 可以是 Condition、LogicCondition、Exists、Expr 或 Not
 	Operator   string // "AND" 或 "OR"
 }
 
 var (
-	operatorList         = []string{"LIKE", "=", ">=", ">", "<=", "<", "IN", "NOT IN"} // 数据库支持的类型
-	likeUseReplaceList   = []string{"%", "_"}                                          //like需要替换的字符
-	likeUseEscapeList    = []string{"/", "&", "#", "@", "^", "$", "!"}                 //定义可以使用的escape列表
+	likeUseReplaceList   = []string{"%", "_"}                          //like需要替换的字符
+	likeUseEscapeList    = []string{"/", "&", "#", "@", "^", "$", "!"} //定义可以使用的escape列表
 	defaultMapOperator   = "="
 	defaultLogicOperator = "AND"
+
+	countLiteralRegexp    = regexp.MustCompile(`^[0-9]+$`) // COUNT(1) 等整数字面量
+	aggregateColumnRegexp = regexp.MustCompile(`^(?i)(COUNT|SUM|AVG|MIN|MAX)\s*\(\s*(DISTINCT\s+)?([^()\s]+)\s*\)(?:\s+(?:AS\s+)?(\S+))?$`)
 )
 
 type Statement struct {
+	columnPolicy   ColumnPolicy //条件中出现未知字段时的处理方式
+	allowFullTable bool         //是否允许没有where条件的更新和删除
+	dialect        Dialect      //数据库方言，默认为 MySQL
+}
+
+// ConditionError 无效的查询条件
+type ConditionError struct {
+	Condition any    // 被拒绝的条件
+	Reason    string // 拒绝的原因
+}
+
+// Error 错误信息
+func (e *ConditionError) Error() string {
+	switch c := e.Condition.(type) {
+	case Condition:
+		return fmt.Sprintf("invalid condition {%s %s %v}: %s", c.Field, c.Operator, c.Value, e.Reason)
+	case LogicCondition:
+		return fmt.Sprintf("invalid logic condition %s: %s", c.Operator, e.Reason)
+	}
+	return fmt.Sprintf("invalid condition %v: %s", e.Condition, e.Reason)
 }
 
 func (s *Statement) getColumnLikeSql(oldValue string, replaceList []string, escapeList []string) (retValLike string, retEscape string, retSuccess bool) {
@@ -80,118 +107,222 @@ func (s *Statement) GetSqlColumnForLike(oldValue string) (retValLike string, ret
 
 // GenerateWhereClauseByMap 通过Map获取where语句
 func (s *Statement) GenerateWhereClauseByMap(whereMap map[string]any) (string, []any) {
+	return s.GenerateWhereClause(s.logicConditionByMap(whereMap))
+}
+
+// appendConditions 在条件组后追加 AND 条件，条件组为 OR 时将其整体作为一个条件
+func appendConditions(group LogicCondition, conditions ...any) LogicCondition {
+	if len(conditions) == 0 {
+		return group
+	}
+	operator := strings.ToUpper(group.Operator)
+	if len(group.Conditions) == 0 || operator == "" || operator == defaultLogicOperator {
+		group.Conditions = append(append([]any{}, group.Conditions...), conditions...)
+		return group
+	}
+	return LogicCondition{Conditions: append([]any{group}, conditions...)}
+}
+
+// logicConditionByMap 将Map转为And关系的条件组
+func (s *Statement) logicConditionByMap(whereMap map[string]any) LogicCondition {
 	oneLogicCondition := LogicCondition{
 		Conditions: make([]any, 0),
 		Operator:   defaultLogicOperator,
 	}
-	for key, val := range whereMap {
+	//需要对key排序，保证生成的sql稳定
+	keys := lo.Keys(whereMap)
+	sort.Strings(keys)
+	for _, key := range keys {
+		val := whereMap[key]
 		oneCondition := Condition{
 			Field:    key,
 			Operator: s.getFieldOperator(val),
 			Value:    val,
 		}
 
-		s := reflect.ValueOf(val)
-		if one, ok := s.Interface().(Condition); ok {
+		if one, ok := val.(Condition); ok {
 			oneCondition.Operator = one.Operator
 			oneCondition.Value = one.Value
 		}
 		oneLogicCondition.Conditions = append(oneLogicCondition.Conditions, oneCondition)
 	}
-	return s.GenerateWhereClause(oneLogicCondition)
+	return oneLogicCondition
 }
 
 func (s *Statement) getFieldOperator(val any) string {
-	if reflect.TypeOf(val).Kind() == reflect.Slice {
+	if isNilValue(val) {
+		return "IS NULL"
+	}
+	if isRangeValue(val) {
+		return "BETWEEN"
+	}
+	if isListValue(val) {
 		return "IN"
 	}
 	return defaultMapOperator
 }
 
-// GenerateWhereClause 生成 WHERE 语句
+// GenerateWhereClause 生成 WHERE 语句，无效的条件会被忽略
 func (s *Statement) GenerateWhereClause(group LogicCondition) (string, []any) {
+	sqlStr, dataList, _ := s.generateWhereClause(group, nil, false)
+	return sqlStr, dataList
+}
+
+// GenerateWhereClauseStrict 生成 WHERE 语句，有任何无效的条件都会返回错误，而不是忽略
+// allColumns 不为空时，条件里的字段必须在 allColumns 中
+func (s *Statement) GenerateWhereClauseStrict(group LogicCondition, allColumns ...string) (string, []any, error) {
+	sqlStr, dataList, errs := s.generateWhereClause(group, s.buildFieldNames(allColumns), true)
+	if len(errs) > 0 {
+		return "", nil, errors.Join(errs...)
+	}
+	return sqlStr, dataList, nil
+}
+
+// generateWhereClause 生成 WHERE 语句，strict 为true时收集所有无效的条件
+func (s *Statement) generateWhereClause(group LogicCondition, allColumns []string, strict bool) (string, []any, []error) {
 	if group.Operator == "" {
 		group.Operator = defaultLogicOperator
 	}
 
 	group.Operator = strings.ToUpper(group.Operator)
 
+	errs := make([]error, 0)
+	if strict && group.Operator != "AND" && group.Operator != "OR" {
+		errs = append(errs, &ConditionError{Condition: group, Reason: "logic operator not support: " + group.Operator})
+	}
+
 	var parts []string
 	dataList := make([]any, 0)
 	for _, condTemp := range group.Conditions {
-		switch c := condTemp.(type) {
-		case Condition:
-			sqlStr, tempDataList, err := s.generateWhereFromCondition(c)
-			if err != nil {
-				continue
-			}
-			if sqlStr != "" {
-				parts = append(parts, fmt.Sprintf("(%s)", sqlStr))
-				dataList = append(dataList, tempDataList...)
-			}
-			continue
-		case LogicCondition:
-			sqlStr, tempDataList := s.GenerateWhereClause(c)
-			if sqlStr != "" {
-				parts = append(parts, fmt.Sprintf("(%s)", sqlStr))
-				dataList = append(dataList, tempDataList...)
-			}
-			continue
+		sqlStr, tempDataList, tempErrs := s.generateWhereFromNode(condTemp, allColumns, strict)
+		errs = append(errs, tempErrs...)
+		if sqlStr != "" {
+			parts = append(parts, fmt.Sprintf("(%s)", sqlStr))
+			dataList = append(dataList, tempDataList...)
 		}
 	}
 	if len(parts) == 0 {
-		return "", dataList
+		return "", dataList, errs
 	}
-	return strings.Join(parts, fmt.Sprintf(" %s ", group.Operator)), dataList
+	return strings.Join(parts, fmt.Sprintf(" %s ", group.Operator)), dataList, errs
 }
 
-// generateWhereClause 生成 WHERE 语句
-func (s *Statement) generateWhereFromCondition(con Condition) (string, []any, error) {
-	con.Operator = strings.ToUpper(con.Operator)
-
-	//如果val是数组，则operator只能是in
-	if reflect.TypeOf(con.Value).Kind() == reflect.Slice {
-		s := reflect.ValueOf(con.Value)
-		//需要去重处理
-		paramList := make([]string, 0)
-		dataList := make([]any, 0)
-		onlyArray := make([]string, 0)
-		for i := 0; i < s.Len(); i++ {
-			ele := s.Index(i).Interface()
-			tempOne := conv.String(ele)
-			if ret, _ := cond.Contains(onlyArray, tempOne); !ret {
-				onlyArray = utils.AppendUniq(onlyArray, tempOne)
-				paramList = append(paramList, "?")
-				dataList = append(dataList, ele)
-			}
+// generateWhereFromNode 生成 LogicCondition.Conditions 中单个节点的 WHERE 语句
+func (s *Statement) generateWhereFromNode(node any, allColumns []string, strict bool) (string, []any, []error) {
+	errs := make([]error, 0)
+	addError := func(reason string) {
+		if strict {
+			errs = append(errs, &ConditionError{Condition: node, Reason: reason})
+		}
+	}
+
+	switch c := node.(type) {
+	case Condition:
+		if strict && len(allColumns) > 0 && lo.IndexOf(allColumns, s.buildOneFieldName(c.Field)) < 0 {
+			addError("field not in columns")
+			return "", nil, errs
+		}
+		if column, ok := c.Value.(Column); ok && strict && len(allColumns) > 0 &&
+			lo.IndexOf(allColumns, s.buildOneFieldName(string(column))) < 0 {
+			addError("column value not in columns")
+			return "", nil, errs
 		}
-		if len(dataList) > 0 {
-			//只能为IN，NOT IN
-			opt := "IN"
-			if con.Operator != "" && con.Operator == "NOT IN" {
-				opt = con.Operator
+		sqlStr, dataList, err := s.generateWhereFromCondition(c)
+		if err != nil {
+			addError(err.Error())
+			return "", nil, errs
+		}
+		return sqlStr, dataList, errs
+	case LogicCondition:
+		return s.generateWhereClause(c, allColumns, strict)
+	case Exists:
+		sqlStr, dataList, err := c.build()
+		if err != nil {
+			addError(err.Error())
+			return "", nil, errs
+		}
+		return sqlStr, dataList, errs
+	case Expr:
+		sqlStr, dataList, err := c.build()
+		if err != nil {
+			addError(err.Error())
+			return "", nil, errs
+		}
+		return sqlStr, dataList, errs
+	case Not:
+		sqlStr, dataList, tempErrs := s.generateWhereFromNode(c.Condition, allColumns, strict)
+		errs = append(errs, tempErrs...)
+		if sqlStr == "" {
+			if len(tempErrs) == 0 {
+				addError("not condition is empty")
 			}
-			return fmt.Sprintf("`%s` %s (%s)", con.Field, opt, strings.Join(paramList, ",")), dataList, nil
+			return "", nil, errs
 		}
-		return "", []any{}, fmt.Errorf("list is empty")
+		return fmt.Sprintf("NOT (%s)", sqlStr), dataList, errs
 	}
+	addError(fmt.Sprintf("condition type not support: %T", node))
+	return "", nil, errs
+}
 
+// generateWhereFromCondition 生成单个条件的 WHERE 语句
+func (s *Statement) generateWhereFromCondition(con Condition) (string, []any, error) {
+	con.Operator = normalizeOperator(con.Operator)
 	if con.Operator == "" {
 		con.Operator = defaultMapOperator
 	}
 
+	// = 和 != 的值为nil时，转为 IS NULL / IS NOT NULL
+	if isNilValue(con.Value) {
+		switch con.Operator {
+		case "=":
+			con.Operator = "IS NULL"
+		case "!=", "<>":
+			con.Operator = "IS NOT NULL"
+		}
+	}
+
+	// = 和 != 的值为 Range 时，转为 BETWEEN / NOT BETWEEN
+	if isRangeValue(con.Value) {
+		switch con.Operator {
+		case "=":
+			con.Operator = "BETWEEN"
+		case "!=", "<>":
+			con.Operator = "NOT BETWEEN"
+		}
+	}
+
 	//必须是支持的类型，乱传不支持的类型则跳过
-	if ok, _ := cond.Contains(operatorList, con.Operator); !ok {
+	op, ok := GetOperator(con.Operator)
+	if !ok {
 		return "", []any{}, fmt.Errorf("operator not support: %s", con.Operator)
 	}
 
-	if con.Operator == "LIKE" {
-		// 这里需要对value进行特殊处理，不能处理，会造成正确的%也会换掉了，就会造成错误
-		//valLike, newVal := s.getSqlColumnForLike(conv.String(con.Value))
-		return fmt.Sprintf("`%s` %s ?", con.Field, con.Operator), []any{con.Value}, nil
+	if !op.NoValue {
+		if isNilValue(con.Value) {
+			return "", []any{}, fmt.Errorf("operator %s not support nil value", con.Operator)
+		}
+		//如果val是数组，= 转为 IN，!= 转为 NOT IN，其他不接收数组的操作符返回错误
+		if isListValue(con.Value) && !op.ListValue {
+			opName := ""
+			switch con.Operator {
+			case "=":
+				opName = "IN"
+			case "!=", "<>":
+				opName = "NOT IN"
+			default:
+				return "", []any{}, fmt.Errorf("operator %s does not accept a list", con.Operator)
+			}
+			if op, ok = GetOperator(opName); !ok {
+				return "", []any{}, fmt.Errorf("operator not support: %s", opName)
+			}
+		}
 	}
 
-	return fmt.Sprintf("`%s` %s ?", con.Field, con.Operator), []any{con.Value}, nil
+	field, err := QuoteIdentifier(con.Field)
+	if err != nil {
+		return "", []any{}, err
+	}
+	return op.Builder(field, op.Name, con.Value)
 }
 
 // buildFieldNames 需要将 `name` 转为 name
@@ -207,6 +338,73 @@ func (s *Statement) buildOneFieldName(oneField string) string {
 	return oneField
 }
 
+// buildSelectColumns 生成查询的字段，只保留在 allColumns 中的字段，支持别名和聚合函数
+// 不是合法标识符或聚合函数的字段返回错误，没有可查询的字段时也返回错误
+func (s *Statement) buildSelectColumns(allColumns []string, selectStr string) (string, error) {
+	if strings.TrimSpace(selectStr) == "" || strings.TrimSpace(selectStr) == "*" {
+		return "*", nil
+	}
+	selectList := strings.Split(selectStr, ",")
+	newSelectList := make([]string, 0)
+	for _, item := range selectList {
+		if aggregate, ok := s.buildAggregateColumn(allColumns, item); ok {
+			newSelectList = append(newSelectList, aggregate)
+			continue
+		}
+		name, _, err := SplitAlias(item)
+		if err != nil {
+			return "", err
+		}
+		if lo.IndexOf(allColumns, s.buildOneFieldName(name)) < 0 {
+			continue
+		}
+		quoted, err := QuoteIdentifierWithAlias(item)
+		if err != nil {
+			return "", err
+		}
+		newSelectList = append(newSelectList, quoted)
+	}
+	if len(newSelectList) == 0 {
+		return "", fmt.Errorf("select columns is empty: %s", selectStr)
+	}
+	return strings.Join(newSelectList, ", "), nil
+}
+
+// buildAggregateColumn 生成聚合函数字段，如 COUNT(*) AS cnt, SUM(DISTINCT age)
+func (s *Statement) buildAggregateColumn(allColumns []string, item string) (string, bool) {
+	matches := aggregateColumnRegexp.FindStringSubmatch(strings.TrimSpace(item))
+	if matches == nil {
+		return "", false
+	}
+	funcName, distinct, column, alias := strings.ToUpper(matches[1]), matches[2], matches[3], matches[4]
+	if column == "*" || countLiteralRegexp.MatchString(column) {
+		if funcName != "COUNT" || distinct != "" {
+			return "", false
+		}
+	} else {
+		if lo.IndexOf(allColumns, s.buildOneFieldName(column)) < 0 {
+			return "", false
+		}
+		quoted, err := QuoteIdentifier(column)
+		if err != nil {
+			return "", false
+		}
+		column = quoted
+	}
+	if distinct != "" {
+		column = "DISTINCT " + column
+	}
+	ret := fmt.Sprintf("%s(%s)", funcName, column)
+	if alias != "" {
+		alias = s.buildOneFieldName(alias)
+		if !IsValidIdentifier(alias) {
+			return "", false
+		}
+		ret = fmt.Sprintf("%s AS %s%s%s", ret, identifierQuote, alias, identifierQuote)
+	}
+	return ret, true
+}
+
 // getColumnListAndDataList 获取数据库的字段列表与数据
 func (s *Statement) getColumnListAndDataList(fieldNames []string, columnMap map[string]any) ([]string, []any) {
 	fieldNamesTemp := s.buildFieldNames(fieldNames)
@@ -214,6 +412,7 @@ func (s *Statement) getColumnListAndDataList(fieldNames []string, columnMap map[
 	columnList := make([]string, 0)
 	// 必须检查是数据库的字段名，避免传错的名字
 	columns := lo.Keys(columnMap)
+	sort.Strings(columns)
 	lo.ForEach(columns, func(column string, index int) {
 		if lo.IndexOf(fieldNamesTemp, column) >= 0 {
 			columnList = append(columnList, column)
@@ -232,156 +431,237 @@ func (s *Statement) getColumnListAndDataList(fieldNames []string, columnMap map[
 
 // InsertSql 插入的sql语句
 func (s *Statement) InsertSql(tableName string, allColumns []string, insertMap map[string]any) (string, []any) {
+	if _, ok := s.getDialect().(mysqlDialect); !ok {
+		//INSERT ... SET 只有 MySQL 支持
+		list, err := s.batchInsertSql(tableName, allColumns, []map[string]any{insertMap}, nil)
+		if err != nil || len(list) == 0 {
+			return "", []any{}
+		}
+		return list[0].Sql, list[0].Args
+	}
 	allColumns = s.buildFieldNames(allColumns)
 	columnList, columnDataList := s.getColumnListAndDataList(allColumns, insertMap)
 	if len(columnList) == 0 {
 		return "", columnDataList
 	}
-	tableName = addCodeForOneColumn(tableName)
-	columnList = lo.Map(columnList, func(item string, index int) string {
-		return addCodeForOneColumn(item)
-	})
+	tableName, err := addCodeForOneColumn(tableName)
+	if err != nil {
+		return "", []any{}
+	}
+	columnList, err = addCodeForColumns(columnList)
+	if err != nil {
+		return "", []any{}
+	}
 	query := fmt.Sprintf("INSERT INTO %s SET %s", tableName, strings.Join(columnList, "=?,")+"=?")
 	return query, columnDataList
 }
 
-// UpdateSql 更新的sql语句
+// UpdateSql 更新的sql语句，没有where条件时需设置 SetStatementAllowFullTable
 func (s *Statement) UpdateSql(tableName string, allColumns []string, updateMap map[string]any, whereMap map[string]any) (string, []any) {
+	query, dataList, err := s.UpdateSqlE(tableName, allColumns, updateMap, whereMap)
+	if err != nil {
+		return "", []any{}
+	}
+	return query, dataList
+}
+
+// UpdateSqlE 与 UpdateSql 相同，不能生成时返回原因，没有where条件时为 ErrFullTableWrite
+func (s *Statement) UpdateSqlE(tableName string, allColumns []string, updateMap map[string]any, whereMap map[string]any) (string, []any, error) {
+	query, dataList, err := s.updateSql(tableName, allColumns, updateMap, s.logicConditionByMap(whereMap))
+	if err != nil {
+		return "", nil, err
+	}
+	query, dataList = s.rebind(query, dataList)
+	return query, dataList, nil
+}
+
+// UpdateSqlByWhereCondition 更新的sql语句，没有where条件时需设置 SetStatementAllowFullTable
+func (s *Statement) UpdateSqlByWhereCondition(tableName string, allColumns []string, updateMap map[string]any, whereCondition LogicCondition) (string, []any) {
+	query, dataList, err := s.UpdateSqlByWhereConditionE(tableName, allColumns, updateMap, whereCondition)
+	if err != nil {
+		return "", []any{}
+	}
+	return query, dataList
+}
+
+// UpdateSqlByWhereConditionE 与 UpdateSqlByWhereCondition 相同，不能生成时返回原因，没有where条件时为 ErrFullTableWrite
+func (s *Statement) UpdateSqlByWhereConditionE(tableName string, allColumns []string, updateMap map[string]any, whereCondition LogicCondition) (string, []any, error) {
+	query, dataList, err := s.updateSql(tableName, allColumns, updateMap, whereCondition)
+	if err != nil {
+		return "", nil, err
+	}
+	query, dataList = s.rebind(query, dataList)
+	return query, dataList, nil
+}
+
+// updateSql 生成更新语句，没有where条件且不允许更新全表时返回 ErrFullTableWrite
+func (s *Statement) updateSql(tableName string, allColumns []string, updateMap map[string]any, whereCondition LogicCondition) (string, []any, error) {
 	allColumns = s.buildFieldNames(allColumns)
 
 	columnList, columnDataList := s.getColumnListAndDataList(allColumns, updateMap)
 	if len(columnList) == 0 {
-		return "", columnDataList
+		return "", nil, fmt.Errorf("update columns is empty")
 	}
 
-	//过滤key
-	whereNewMap := make(map[string]any)
-	for k, v := range whereMap {
-		if lo.IndexOf(allColumns, k) >= 0 {
-			whereNewMap[k] = v
-		}
+	whereString, whereDataList, err := s.fullTableWhere(whereCondition, s.tableColumns(tableName, allColumns))
+	if err != nil {
+		return "", nil, err
 	}
 
-	for i, column := range columnList {
-		columnList[i] = addCodeForOneColumn(column)
+	tableName, err = addCodeForOneColumn(tableName)
+	if err != nil {
+		return "", nil, err
+	}
+	columnList, err = addCodeForColumns(columnList)
+	if err != nil {
+		return "", nil, err
 	}
 
-	tableName = addCodeForOneColumn(tableName)
-	columnList = lo.Map(columnList, func(item string, index int) string {
-		return addCodeForOneColumn(item)
-	})
+	//值可以是 Expr、UpdateExpr 等表达式
+	setList := make([]string, 0, len(columnList))
+	dataList := make([]any, 0, len(columnDataList)+len(whereDataList))
+	for i, column := range columnList {
+		value, args, err := assignmentValue(s.getDialect(), column, columnDataList[i])
+		if err != nil {
+			return "", nil, err
+		}
+		setList = append(setList, column+"="+value)
+		dataList = append(dataList, args...)
+	}
 
-	whereString, whereDataList := s.GenerateWhereClauseByMap(whereNewMap)
 	if len(whereString) == 0 {
 		//没有where语句
-		query := fmt.Sprintf("UPDATE %s SET %s", tableName, strings.Join(columnList, "=?,")+"=?")
-		return query, columnDataList
+		query := fmt.Sprintf("UPDATE %s SET %s", tableName, strings.Join(setList, ","))
+		return query, dataList, nil
 	}
-	columnDataList = append(columnDataList, whereDataList...)
-	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s", tableName, strings.Join(columnList, "=?,")+"=?", whereString)
-	return query, columnDataList
+	dataList = append(dataList, whereDataList...)
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s", tableName, strings.Join(setList, ","), whereString)
+	return query, dataList, nil
 }
 
-// UpdateSqlByWhereCondition 更新的sql语句
-func (s *Statement) UpdateSqlByWhereCondition(tableName string, allColumns []string, updateMap map[string]any, whereCondition LogicCondition) (string, []any) {
-	query, updateColumnDataList := s.UpdateSql(tableName, allColumns, updateMap, map[string]any{})
-	whereStr, whereDataList := s.GenerateWhereClause(whereCondition)
-	if whereStr == "" {
-		return query, updateColumnDataList
+// SelectSql 查询的sql语句
+func (s *Statement) SelectSql(tableName string, allColumns []string, selectStr string, whereMap map[string]any, offset, limit int, opts ...SelectOption) (string, []any) {
+	query, dataList, err := s.SelectSqlE(tableName, allColumns, selectStr, whereMap, offset, limit, opts...)
+	if err != nil {
+		return "", []any{}
 	}
-	query = fmt.Sprintf("%s WHERE %s", query, whereStr)
-	updateColumnDataList = append(updateColumnDataList, whereDataList...)
-	return query, updateColumnDataList
+	return query, dataList
 }
 
-// SelectSql 查询的sql语句
-func (s *Statement) SelectSql(tableName string, allColumns []string, selectStr string, whereMap map[string]any, offset, limit int) (string, []any) {
-	allColumns = s.buildFieldNames(allColumns)
+// SelectSqlE 与 SelectSql 相同，不能生成时返回原因
+func (s *Statement) SelectSqlE(tableName string, allColumns []string, selectStr string, whereMap map[string]any, offset, limit int, opts ...SelectOption) (string, []any, error) {
+	return s.SelectSqlByWhereConditionE(tableName, allColumns, selectStr, s.logicConditionByMap(whereMap), offset, limit, opts...)
+}
 
-	if selectStr == "" {
-		selectStr = "*"
-	} else {
-		selectList := strings.Split(selectStr, ",")
-		newSelectList := make([]string, 0)
-		lo.ForEach(selectList, func(item string, index int) {
-			item = strings.TrimSpace(item)
-			if lo.IndexOf(allColumns, item) >= 0 {
-				item = s.buildOneFieldName(item)
-				newSelectList = append(newSelectList, item)
-			}
-		})
-		if len(newSelectList) > 0 {
-			selectStr = strings.Join(newSelectList, "`, `")
-			selectStr = fmt.Sprintf("`%s`", selectStr)
-		} else {
-			//表示要查询 count(*) 等，就不用管了
-		}
+// SelectSqlByWhereCondition 查询的sql语句
+func (s *Statement) SelectSqlByWhereCondition(tableName string, allColumns []string, selectStr string, whereCondition LogicCondition, offset, num int, opts ...SelectOption) (string, []any) {
+	query, dataList, err := s.SelectSqlByWhereConditionE(tableName, allColumns, selectStr, whereCondition, offset, num, opts...)
+	if err != nil {
+		return "", []any{}
 	}
+	return query, dataList
+}
 
-	//过滤key
-	whereNewMap := make(map[string]any)
-	for k, v := range whereMap {
-		if lo.IndexOf(allColumns, k) >= 0 {
-			whereNewMap[k] = v
-		}
+// SelectSqlByWhereConditionE 与 SelectSqlByWhereCondition 相同，不能生成时返回原因
+func (s *Statement) SelectSqlByWhereConditionE(tableName string, allColumns []string, selectStr string, whereCondition LogicCondition, offset, num int, opts ...SelectOption) (string, []any, error) {
+	allColumns = s.buildFieldNames(allColumns)
+	allowed := newSelectConfig(opts...).allowedColumns(tableName, allColumns)
+	whereStr, whereDataList, err := s.whereByColumns(whereCondition, allowed, false)
+	if err != nil {
+		return "", nil, err
+	}
+	query, dataList, err := s.selectSql(tableName, allColumns, selectStr, whereStr, whereDataList, offset, num, opts...)
+	if err != nil {
+		return "", nil, err
 	}
-	tableName = addCodeForOneColumn(tableName)
+	query, dataList = s.rebind(query, dataList)
+	return query, dataList, nil
+}
 
-	whereString, whereDataList := s.GenerateWhereClauseByMap(whereNewMap)
-	query := fmt.Sprintf("SELECT %s FROM %s", selectStr, tableName)
-	if whereString != "" {
-		query = fmt.Sprintf("%s WHERE %s", query, whereString)
+// selectSql 拼接查询语句
+func (s *Statement) selectSql(tableName string, allColumns []string, selectStr string, whereStr string, whereDataList []any, offset, limit int, opts ...SelectOption) (string, []any, error) {
+	config := newSelectConfig(opts...)
+	allowed := config.allowedColumns(tableName, allColumns)
+	from, dataList, err := config.buildFrom(s, tableName, allowed, false)
+	if err != nil {
+		return "", nil, err
+	}
+	clause, err := config.buildClause(s, allowed, selectStr, false)
+	if err != nil {
+		return "", nil, err
+	}
+
+	selectStr, err = s.buildSelectColumns(allowed, selectStr)
+	if err != nil {
+		return "", nil, err
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s", selectStr, from)
+	if whereStr != "" {
+		query = fmt.Sprintf("%s WHERE %s", query, whereStr)
+		dataList = append(dataList, whereDataList...)
+	}
+	if clauseStr := clause.String(); clauseStr != "" {
+		query = fmt.Sprintf("%s %s", query, clauseStr)
+		dataList = append(dataList, clause.havingArgs...)
 	}
 	if offset >= 0 && limit > 0 {
-		query = fmt.Sprintf("%s LIMIT %d, %d", query, offset, limit)
+		query = fmt.Sprintf("%s %s", query, s.getDialect().LimitOffset(offset, limit))
 	}
 
-	return query, whereDataList
+	return query, dataList, nil
 }
 
-// SelectSqlByWhereCondition 查询的sql语句
-func (s *Statement) SelectSqlByWhereCondition(tableName string, allColumns []string, selectStr string, whereCondition LogicCondition, offset, num int) (string, []any) {
-	query, selectDataList := s.SelectSql(tableName, allColumns, selectStr, map[string]any{}, 0, 0)
-	whereStr, whereDataList := s.GenerateWhereClause(whereCondition)
-	if whereStr == "" {
-		return query, selectDataList
+// DeleteSql 删除的sql语句，没有where条件时需设置 SetStatementAllowFullTable
+func (s *Statement) DeleteSql(tableName string, allColumns []string, whereMap map[string]any) (string, []any) {
+	query, dataList, err := s.DeleteSqlE(tableName, allColumns, whereMap)
+	if err != nil {
+		return "", []any{}
 	}
-	query = fmt.Sprintf("%s WHERE %s", query, whereStr)
-	if offset >= 0 && num > 0 {
-		query = fmt.Sprintf("%s LIMIT %d, %d", query, offset, num)
+	return query, dataList
+}
+
+// DeleteSqlE 与 DeleteSql 相同，不能生成时返回原因，没有where条件时为 ErrFullTableWrite
+func (s *Statement) DeleteSqlE(tableName string, allColumns []string, whereMap map[string]any) (string, []any, error) {
+	query, dataList, err := s.deleteSql(tableName, allColumns, s.logicConditionByMap(whereMap))
+	if err != nil {
+		return "", nil, err
 	}
-	selectDataList = append(selectDataList, whereDataList...)
-	return query, selectDataList
+	query, dataList = s.rebind(query, dataList)
+	return query, dataList, nil
 }
 
-// DeleteSql 删除的sql语句
-func (s *Statement) DeleteSql(tableName string, allColumns []string, whereMap map[string]any) (string, []any) {
-	allColumns = s.buildFieldNames(allColumns)
+// DeleteSqlByWhereCondition 删除的sql语句，没有where条件时需设置 SetStatementAllowFullTable
+func (s *Statement) DeleteSqlByWhereCondition(tableName string, allColumns []string, whereCondition LogicCondition) (string, []any) {
+	query, dataList, err := s.DeleteSqlByWhereConditionE(tableName, allColumns, whereCondition)
+	if err != nil {
+		return "", []any{}
+	}
+	return query, dataList
+}
 
-	//过滤key
-	whereNewMap := make(map[string]any)
-	for k, v := range whereMap {
-		if lo.IndexOf(allColumns, k) >= 0 {
-			whereNewMap[k] = v
-		}
+// DeleteSqlByWhereConditionE 与 DeleteSqlByWhereCondition 相同，不能生成时返回原因，没有where条件时为 ErrFullTableWrite
+func (s *Statement) DeleteSqlByWhereConditionE(tableName string, allColumns []string, whereCondition LogicCondition) (string, []any, error) {
+	query, dataList, err := s.deleteSql(tableName, allColumns, whereCondition)
+	if err != nil {
+		return "", nil, err
+	}
+	query, dataList = s.rebind(query, dataList)
+	return query, dataList, nil
+}
+
+// deleteSql 生成删除语句，没有where条件且不允许删除全表时返回 ErrFullTableWrite
+func (s *Statement) deleteSql(tableName string, allColumns []string, whereCondition LogicCondition) (string, []any, error) {
+	whereString, whereDataList, err := s.fullTableWhere(whereCondition, s.tableColumns(tableName, allColumns))
+	if err != nil {
+		return "", nil, err
+	}
+	tableName, err = addCodeForOneColumn(tableName)
+	if err != nil {
+		return "", nil, err
 	}
-	whereString, whereDataList := s.GenerateWhereClauseByMap(whereNewMap)
-	tableName = addCodeForOneColumn(tableName)
 	query := fmt.Sprintf("DELETE FROM %s", tableName)
 	if whereString != "" {
 		query = fmt.Sprintf("%s WHERE %s", query, whereString)
 	}
-	return query, whereDataList
-}
-
-// DeleteSqlByWhereCondition 删除的sql语句
-func (s *Statement) DeleteSqlByWhereCondition(tableName string, allColumns []string, whereCondition LogicCondition) (string, []any) {
-	query, deleteDataList := s.DeleteSql(tableName, allColumns, map[string]any{})
-	whereStr, whereDataList := s.GenerateWhereClause(whereCondition)
-	if whereStr == "" {
-		return query, deleteDataList
-	}
-	query = fmt.Sprintf("%s WHERE %s", query, whereStr)
-	deleteDataList = append(deleteDataList, whereDataList...)
-	return query, deleteDataList
+	return query, whereDataList, nil
 }
@@ -0,0 +1,237 @@
+package sqlstatement
+
+import (
+	"fmt"
+	"github.com/samber/lo"
+	"github.com/tianlin0/go-plat-utils/conv"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxPlaceholders = 65535           // mysql 预处理语句支持的最大占位符数量
+	defaultMaxPacketSize   = 4 * 1024 * 1024 // mysql 5.7 默认的 max_allowed_packet
+)
+
+// BatchSql 批量操作生成的一条sql语句
+type BatchSql struct {
+	Sql  string
+	Args []any
+}
+
+// BatchOption 批量操作的可选项
+type BatchOption func(*batchConfig)
+
+type batchConfig struct {
+	maxPlaceholders int // 每条语句最多的占位符数量
+	maxPacketSize   int // 每条语句预估的最大字节数
+	maxRows         int // 每条语句最多的行数，0表示不限制
+}
+
+// SetMaxPlaceholders 设置每条语句最多的占位符数量，默认为 65535
+func SetMaxPlaceholders(num int) BatchOption {
+	return func(c *batchConfig) {
+		c.maxPlaceholders = num
+	}
+}
+
+// SetMaxPacketSize 设置每条语句预估的最大字节数，应不大于 max_allowed_packet，默认为 4M
+func SetMaxPacketSize(size int) BatchOption {
+	return func(c *batchConfig) {
+		c.maxPacketSize = size
+	}
+}
+
+// SetMaxRows 设置每条语句最多的行数
+func SetMaxRows(num int) BatchOption {
+	return func(c *batchConfig) {
+		c.maxRows = num
+	}
+}
+
+func newBatchConfig(opts ...BatchOption) *batchConfig {
+	c := &batchConfig{
+		maxPlaceholders: defaultMaxPlaceholders,
+		maxPacketSize:   defaultMaxPacketSize,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(c)
+		}
+	}
+	if c.maxPlaceholders <= 0 || c.maxPlaceholders > defaultMaxPlaceholders {
+		c.maxPlaceholders = defaultMaxPlaceholders
+	}
+	if c.maxPacketSize <= 0 {
+		c.maxPacketSize = defaultMaxPacketSize
+	}
+	return c
+}
+
+// batchDefault 表示该行没有这个字段，插入时使用 DEFAULT
+type batchDefault struct{}
+
+// batchInsert 批量插入的语句结构
+type batchInsert struct {
+	verb       string   // INSERT INTO / INSERT IGNORE INTO / REPLACE INTO
+	tableName  string   // 已转义的表名
+	columns    []string // 已转义的列名
+	rows       [][]any  // 每行的值，与 columns 对应
+	suffix     string   // 拼接在 VALUES 之后的语句，如 ON DUPLICATE KEY UPDATE
+	suffixArgs []any
+	noDefault  bool // 不支持 VALUES 中使用 DEFAULT，如 SQLite
+}
+
+// estimateArgSize 预估参数在报文中占用的字节数
+func estimateArgSize(arg any) int {
+	switch v := arg.(type) {
+	case nil, batchDefault:
+		return 8
+	case string:
+		return len(v) + 2
+	case []byte:
+		return len(v)*2 + 3
+	case time.Time:
+		return 28
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return 21
+	}
+	return len(conv.String(arg)) + 2
+}
+
+// collectInsertRows 获取所有行的字段并集（需在 allColumns 中），并按字段排序得到每行的值
+func (s *Statement) collectInsertRows(allColumns []string, rows []map[string]any) ([]string, [][]any) {
+	allColumns = s.buildFieldNames(allColumns)
+	columnMap := make(map[string]bool)
+	for _, row := range rows {
+		for key := range row {
+			if lo.IndexOf(allColumns, key) >= 0 {
+				columnMap[key] = true
+			}
+		}
+	}
+	columns := lo.Keys(columnMap)
+	sort.Strings(columns)
+
+	values := make([][]any, 0, len(rows))
+	for _, row := range rows {
+		oneRow := make([]any, 0, len(columns))
+		for _, column := range columns {
+			if val, ok := row[column]; ok {
+				oneRow = append(oneRow, val)
+			} else {
+				oneRow = append(oneRow, batchDefault{})
+			}
+		}
+		values = append(values, oneRow)
+	}
+	return columns, values
+}
+
+// build 按占位符数量和报文大小拆分为多条语句
+func (b *batchInsert) build(config *batchConfig) ([]BatchSql, error) {
+	if len(b.columns) == 0 || len(b.rows) == 0 {
+		return nil, fmt.Errorf("insert columns or rows is empty")
+	}
+	head := fmt.Sprintf("%s %s (%s) VALUES ", b.verb, b.tableName, strings.Join(b.columns, ","))
+	baseSize := len(head) + len(b.suffix)
+	basePlaceholders := countPlaceholders(b.suffix)
+	for _, arg := range b.suffixArgs {
+		baseSize += estimateArgSize(arg)
+	}
+
+	ret := make([]BatchSql, 0)
+	rowSqlList := make([]string, 0)
+	args := make([]any, 0)
+	size, placeholders := baseSize, basePlaceholders
+
+	flush := func() {
+		if len(rowSqlList) == 0 {
+			return
+		}
+		allArgs := append(args, b.suffixArgs...)
+		ret = append(ret, BatchSql{
+			Sql:  head + strings.Join(rowSqlList, ",") + b.suffix,
+			Args: allArgs,
+		})
+		rowSqlList = make([]string, 0)
+		args = make([]any, 0)
+		size, placeholders = baseSize, basePlaceholders
+	}
+
+	for _, row := range b.rows {
+		paramList := make([]string, 0, len(row))
+		rowArgs := make([]any, 0, len(row))
+		rowSize := 3
+		for _, val := range row {
+			rowSize += estimateArgSize(val) + 1
+			if _, ok := val.(batchDefault); ok {
+				if b.noDefault {
+					return nil, fmt.Errorf("rows must have the same columns when DEFAULT is not supported")
+				}
+				paramList = append(paramList, "DEFAULT")
+				continue
+			}
+			paramList = append(paramList, "?")
+			rowArgs = append(rowArgs, val)
+		}
+		if len(rowArgs)+basePlaceholders > config.maxPlaceholders {
+			return nil, fmt.Errorf("row has too many placeholders: %d", len(rowArgs))
+		}
+		if len(rowSqlList) > 0 && (placeholders+len(rowArgs) > config.maxPlaceholders ||
+			size+rowSize > config.maxPacketSize || (config.maxRows > 0 && len(rowSqlList) >= config.maxRows)) {
+			flush()
+		}
+		rowSqlList = append(rowSqlList, "("+strings.Join(paramList, ",")+")")
+		args = append(args, rowArgs...)
+		size += rowSize
+		placeholders += len(rowArgs)
+	}
+	flush()
+	return ret, nil
+}
+
+// BatchInsertSql 批量插入的sql语句，字段为所有行中在 allColumns 里的字段并集，某行缺少的字段使用 DEFAULT
+// 超过占位符数量或报文大小限制时会拆分为多条语句
+func (s *Statement) BatchInsertSql(tableName string, allColumns []string, rows []map[string]any, opts ...BatchOption) []BatchSql {
+	list, err := s.batchInsertSql(tableName, allColumns, rows, nil, opts...)
+	if err != nil {
+		return []BatchSql{}
+	}
+	return list
+}
+
+// batchInsertSql 生成批量插入的语句，upsert 不为nil时处理冲突
+func (s *Statement) batchInsertSql(tableName string, allColumns []string, rows []map[string]any, upsert *Upsert, opts ...BatchOption) ([]BatchSql, error) {
+	columns, values := s.collectInsertRows(allColumns, rows)
+	quotedTable, err := addCodeForOneColumn(tableName)
+	if err != nil {
+		return nil, err
+	}
+	quotedColumns, err := addCodeForColumns(columns)
+	if err != nil {
+		return nil, err
+	}
+	d := s.getDialect()
+	ins := &batchInsert{
+		verb:      "INSERT INTO",
+		tableName: quotedTable,
+		columns:   quotedColumns,
+		rows:      values,
+		noDefault: d.Name() == DialectSQLite.Name(),
+	}
+	if upsert != nil {
+		if err = upsert.apply(ins, d, tableName, allColumns, columns); err != nil {
+			return nil, err
+		}
+	}
+	list, err := ins.build(newBatchConfig(opts...))
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		list[i].Sql = rebindSql(d, list[i].Sql)
+	}
+	return list, nil
+}
@@ -0,0 +1,160 @@
+package sqlstatement
+
+import (
+	"fmt"
+	"github.com/samber/lo"
+	"github.com/tianlin0/go-plat-utils/conv"
+	"strings"
+)
+
+// bulkUpdateRow 批量更新的一行，values 与更新的字段对应，batchDefault 表示该行不更新这个字段
+type bulkUpdateRow struct {
+	key    any
+	values []any
+	size   int
+	args   int
+}
+
+// BulkUpdateSql 按 keyColumn 将多行分别更新为各自的值，生成
+// UPDATE t SET a = CASE id WHEN ? THEN ? ... ELSE a END WHERE id IN (?, ...)
+// 更新的字段为所有行中在 allColumns 里的字段并集（不含 keyColumn），某行缺少的字段保持原值
+// 值可以使用 Expr、Incr、Decr、Null，超过占位符数量、报文大小或行数限制时会拆分为多条语句
+func (s *Statement) BulkUpdateSql(tableName string, allColumns []string, keyColumn string, rows []map[string]any, opts ...BatchOption) []BatchSql {
+	list, err := s.BulkUpdateSqlE(tableName, allColumns, keyColumn, rows, opts...)
+	if err != nil {
+		return []BatchSql{}
+	}
+	return list
+}
+
+// BulkUpdateSqlE 与 BulkUpdateSql 相同，不能生成时返回原因
+func (s *Statement) BulkUpdateSqlE(tableName string, allColumns []string, keyColumn string, rows []map[string]any, opts ...BatchOption) ([]BatchSql, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("update rows is empty")
+	}
+	keyColumn = s.buildOneFieldName(strings.TrimSpace(keyColumn))
+	if lo.IndexOf(s.buildFieldNames(allColumns), keyColumn) < 0 {
+		return nil, fmt.Errorf("key column not in columns: %s", keyColumn)
+	}
+	columns, values := s.collectInsertRows(allColumns, rows)
+	keyIndex := lo.IndexOf(columns, keyColumn)
+	if keyIndex < 0 || len(columns) < 2 {
+		return nil, fmt.Errorf("update rows must have key column and columns to update")
+	}
+
+	d := s.getDialect()
+	quotedTable, err := addCodeForOneColumn(tableName)
+	if err != nil {
+		return nil, err
+	}
+	quotedKey, err := addCodeForOneColumn(keyColumn)
+	if err != nil {
+		return nil, err
+	}
+	updateColumns := append(append([]string{}, columns[:keyIndex]...), columns[keyIndex+1:]...)
+	quotedColumns, err := addCodeForColumns(updateColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]bool)
+	bulkRows := make([]bulkUpdateRow, 0, len(values))
+	for i, row := range values {
+		key := row[keyIndex]
+		if _, ok := key.(batchDefault); ok || isNilValue(key) {
+			return nil, fmt.Errorf("update row %d has no key column: %s", i, keyColumn)
+		}
+		if keys[conv.String(key)] {
+			return nil, fmt.Errorf("update row %d has duplicate key: %v", i, key)
+		}
+		keys[conv.String(key)] = true
+
+		one := bulkUpdateRow{key: key, values: append(append([]any{}, row[:keyIndex]...), row[keyIndex+1:]...)}
+		one.size, one.args = estimateArgSize(key)*2, 1
+		for j, val := range one.values {
+			if _, ok := val.(batchDefault); ok {
+				continue
+			}
+			if expr, ok := val.(UpdateExpr); ok && expr.op == "DEFAULT" {
+				return nil, fmt.Errorf("update %s to DEFAULT not support in bulk update", updateColumns[j])
+			}
+			_, valueArgs, err := assignmentValue(d, updateColumns[j], val)
+			if err != nil {
+				return nil, err
+			}
+			one.size += estimateArgSize(key) + estimateArgSize(val) + 16
+			one.args += 1 + len(valueArgs)
+		}
+		bulkRows = append(bulkRows, one)
+	}
+
+	config := newBatchConfig(opts...)
+	ret := make([]BatchSql, 0)
+	for _, chunk := range chunkBulkUpdateRows(bulkRows, config, len(quotedTable)+len(quotedKey)*(len(quotedColumns)+1)+32) {
+		one, err := buildBulkUpdate(d, quotedTable, quotedKey, quotedColumns, chunk)
+		if err != nil {
+			return nil, err
+		}
+		one.Sql = rebindSql(d, one.Sql)
+		ret = append(ret, one)
+	}
+	return ret, nil
+}
+
+// chunkBulkUpdateRows 按占位符数量、报文大小和行数拆分
+func chunkBulkUpdateRows(rows []bulkUpdateRow, config *batchConfig, baseSize int) [][]bulkUpdateRow {
+	ret := make([][]bulkUpdateRow, 0)
+	chunk := make([]bulkUpdateRow, 0)
+	size, placeholders := baseSize, 0
+	for _, row := range rows {
+		if len(chunk) > 0 && (placeholders+row.args > config.maxPlaceholders ||
+			size+row.size > config.maxPacketSize || (config.maxRows > 0 && len(chunk) >= config.maxRows)) {
+			ret = append(ret, chunk)
+			chunk = make([]bulkUpdateRow, 0)
+			size, placeholders = baseSize, 0
+		}
+		chunk = append(chunk, row)
+		size += row.size
+		placeholders += row.args
+	}
+	if len(chunk) > 0 {
+		ret = append(ret, chunk)
+	}
+	return ret
+}
+
+// buildBulkUpdate 生成一条 CASE WHEN 的更新语句，所有行都不更新的字段不出现在 SET 中
+func buildBulkUpdate(d Dialect, tableName, keyColumn string, columns []string, rows []bulkUpdateRow) (BatchSql, error) {
+	setList := make([]string, 0, len(columns))
+	args := make([]any, 0)
+	for i, column := range columns {
+		whenList := make([]string, 0, len(rows))
+		for _, row := range rows {
+			if _, ok := row.values[i].(batchDefault); ok {
+				continue
+			}
+			value, valueArgs, err := assignmentValue(d, column, row.values[i])
+			if err != nil {
+				return BatchSql{}, err
+			}
+			whenList = append(whenList, "WHEN ? THEN "+value)
+			args = append(args, row.key)
+			args = append(args, valueArgs...)
+		}
+		if len(whenList) == 0 {
+			continue
+		}
+		setList = append(setList, fmt.Sprintf("%s = CASE %s %s ELSE %s END", column, keyColumn, strings.Join(whenList, " "), column))
+	}
+	if len(setList) == 0 {
+		return BatchSql{}, fmt.Errorf("update columns is empty")
+	}
+
+	inList := make([]string, 0, len(rows))
+	for _, row := range rows {
+		inList = append(inList, "?")
+		args = append(args, row.key)
+	}
+	sqlStr := fmt.Sprintf("UPDATE %s SET %s WHERE %s IN (%s)", tableName, strings.Join(setList, ", "), keyColumn, strings.Join(inList, ","))
+	return BatchSql{Sql: sqlStr, Args: args}, nil
+}
@@ -0,0 +1,118 @@
+package sqlstatement
+
+import (
+	"fmt"
+	"github.com/samber/lo"
+	"strings"
+)
+
+// ColumnPolicy 条件中出现不在 allColumns 中的字段时的处理方式
+type ColumnPolicy int
+
+const (
+	ColumnPolicyStrip  ColumnPolicy = iota // 忽略该条件，默认
+	ColumnPolicyReject                     // 拒绝生成sql
+)
+
+// StatementOption Statement 的可选项
+type StatementOption func(*Statement)
+
+// NewStatement 新建一个对象，直接使用 new(Statement) 时为默认配置
+func NewStatement(opts ...StatementOption) *Statement {
+	s := new(Statement)
+	for _, opt := range opts {
+		if opt != nil {
+			opt(s)
+		}
+	}
+	return s
+}
+
+// SetStatementColumnPolicy 设置条件中出现未知字段时的处理方式
+func SetStatementColumnPolicy(policy ColumnPolicy) StatementOption {
+	return func(s *Statement) {
+		s.columnPolicy = policy
+	}
+}
+
+// isKnownColumn 字段是否在 allColumns 中，qualifier.* 不能作为条件的字段
+func (s *Statement) isKnownColumn(allColumns []string, field string) bool {
+	name := s.buildOneFieldName(strings.TrimSpace(field))
+	return !strings.HasSuffix(name, "*") && lo.IndexOf(allColumns, name) >= 0
+}
+
+// filterCondition 递归检查条件树中的字段，未知字段按 columnPolicy 忽略或返回错误，返回忽略的条件数量
+// Column 类型的值可以引用外部查询的字段，Expr、Exists 为调用方保证安全的sql，均不检查
+func (s *Statement) filterCondition(group LogicCondition, allColumns []string) (LogicCondition, int, error) {
+	return s.filterGroup(group, allColumns, false)
+}
+
+// filterRelationCondition 检查 JOIN 的 ON 和 HAVING 条件，Column 类型的值也必须在 allColumns 中
+func (s *Statement) filterRelationCondition(group LogicCondition, allColumns []string) (LogicCondition, int, error) {
+	return s.filterGroup(group, allColumns, true)
+}
+
+func (s *Statement) filterGroup(group LogicCondition, allColumns []string, checkColumn bool) (LogicCondition, int, error) {
+	conditions := make([]any, 0, len(group.Conditions))
+	stripped := 0
+	for _, one := range group.Conditions {
+		node, num, err := s.filterNode(one, allColumns, checkColumn)
+		if err != nil {
+			return LogicCondition{}, 0, err
+		}
+		stripped += num
+		if node != nil {
+			conditions = append(conditions, node)
+		}
+	}
+	group.Conditions = conditions
+	return group, stripped, nil
+}
+
+func (s *Statement) filterNode(node any, allColumns []string, checkColumn bool) (any, int, error) {
+	switch c := node.(type) {
+	case Condition:
+		reason := ""
+		if !s.isKnownColumn(allColumns, c.Field) {
+			reason = "field not in columns"
+		} else if column, ok := c.Value.(Column); ok && checkColumn && !s.isKnownColumn(allColumns, string(column)) {
+			reason = "column value not in columns"
+		}
+		if reason == "" {
+			return c, 0, nil
+		}
+		if s.columnPolicy == ColumnPolicyReject {
+			return nil, 0, &ConditionError{Condition: c, Reason: reason}
+		}
+		return nil, 1, nil
+	case LogicCondition:
+		return s.filterGroup(c, allColumns, checkColumn)
+	case Not:
+		inner, num, err := s.filterNode(c.Condition, allColumns, checkColumn)
+		if err != nil || inner == nil {
+			return nil, num, err
+		}
+		return Not{Condition: inner}, num, nil
+	}
+	return node, 0, nil
+}
+
+// whereByColumns 按字段策略过滤条件后生成 WHERE 语句
+// refuseEmpty 为true时，如果条件全部被忽略则返回错误，避免更新或删除全表
+func (s *Statement) whereByColumns(group LogicCondition, allColumns []string, refuseEmpty bool) (string, []any, error) {
+	group, stripped, err := s.filterCondition(group, allColumns)
+	if err != nil {
+		return "", nil, err
+	}
+	sqlStr, dataList := s.GenerateWhereClause(group)
+	if sqlStr == "" && stripped > 0 && refuseEmpty {
+		return "", nil, fmt.Errorf("%w: all where conditions are not in columns", ErrFullTableWrite)
+	}
+	return sqlStr, dataList, nil
+}
+
+// tableColumns 条件中允许使用的字段，包括使用表名限定的字段，如 user.id
+func (s *Statement) tableColumns(tableName string, allColumns []string) []string {
+	allColumns = s.buildFieldNames(allColumns)
+	return append(allColumns, qualifyColumns(tableName, allColumns)...)
+}
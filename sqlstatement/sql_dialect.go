@@ -0,0 +1,393 @@
+package sqlstatement
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect 数据库方言，语句先按 MySQL 的格式生成，最后再转换标识符的转义和占位符
+// GenerateWhereClause 等生成的片段保持 MySQL 的格式，由外层的完整语句统一转换，SubQuery 中方言的占位符会转回 ?
+type Dialect interface {
+	Name() string                                                // 方言名称，如 mysql
+	QuoteIdentifier(name string) string                          // 转义单个标识符，name 已校验过
+	Placeholder(index int) string                                // 第 index 个参数的占位符，从1开始
+	LimitOffset(offset, limit int) string                        // 分页语句
+	BoolLiteral(value bool) string                               // 布尔值的字面量，用于 InterpolateForLogDialect
+	Upsert(clause UpsertClause) (verb, suffix string, err error) // 插入冲突时的动词和拼接在 VALUES 之后的语句
+}
+
+// PlaceholderStyle 占位符的格式
+type PlaceholderStyle int
+
+const (
+	PlaceholderQuestion PlaceholderStyle = iota // ?
+	PlaceholderDollar                           // $1
+	PlaceholderColon                            // :p1，按位置生成 :p1..:pN，不使用字段名
+)
+
+var (
+	DialectMySQL      Dialect = mysqlDialect{}
+	DialectPostgreSQL Dialect = postgresDialect{}
+	DialectSQLite     Dialect = sqliteDialect{}
+)
+
+// UpsertAssignment 插入冲突时更新的一个字段
+type UpsertAssignment struct {
+	Column    string // 未转义的列名
+	Increment bool   // 是否累加插入的值
+	Expr      string // 更新的表达式，不为空时忽略 Increment
+}
+
+// UpsertClause 插入冲突时的处理，由 Dialect 生成对应的语句
+type UpsertClause struct {
+	Mode            UpsertMode
+	Table           string   // 未转义的表名
+	ConflictColumns []string // 未转义的冲突判断字段
+	Assignments     []UpsertAssignment
+	RowAlias        string // 插入行的别名，仅 MySQL 使用
+}
+
+// SetStatementDialect 设置数据库方言，默认为 MySQL
+func SetStatementDialect(d Dialect) StatementOption {
+	return func(s *Statement) {
+		s.dialect = d
+	}
+}
+
+// WithPlaceholder 替换方言的占位符格式，如 SQLite 使用 :p1，命名风格的占位符总是按参数位置编号为 :p1..:pN
+func WithPlaceholder(d Dialect, style PlaceholderStyle) Dialect {
+	return placeholderDialect{Dialect: d, style: style}
+}
+
+// getDialect 获取方言，未设置时为 MySQL
+func (s *Statement) getDialect() Dialect {
+	if s.dialect == nil {
+		return DialectMySQL
+	}
+	return s.dialect
+}
+
+// rebind 将 MySQL 格式的语句转为方言格式
+func (s *Statement) rebind(sqlStr string, args []any) (string, []any) {
+	return rebindSql(s.getDialect(), sqlStr), args
+}
+
+// rebindSql 转换反引号转义的标识符和 ? 占位符，忽略字符串中的内容
+func rebindSql(d Dialect, sqlStr string) string {
+	if _, ok := d.(mysqlDialect); ok || sqlStr == "" {
+		return sqlStr
+	}
+	sqlStr, _ = walkSql(sqlStr, d.QuoteIdentifier, func(index int) (string, error) {
+		return d.Placeholder(index), nil
+	})
+	return sqlStr
+}
+
+// unbindSql 将 $n、:pN 占位符转回 ?，参数按占位符出现的顺序排列，字符串和转义的标识符中的内容不处理
+// 用于将方言格式的子查询嵌套到外层语句中，由外层统一编号
+func unbindSql(sqlStr string, args []any) (string, []any, error) {
+	var sb strings.Builder
+	newArgs := make([]any, 0, len(args))
+	numbered, question := false, false
+	for i := 0; i < len(sqlStr); i++ {
+		c := sqlStr[i]
+		switch c {
+		case '\'', '"', '`':
+			j := quotedEnd(sqlStr, i)
+			sb.WriteString(sqlStr[i : j+1])
+			i = j
+			continue
+		case '?':
+			question = true
+		case '$', ':':
+			start := i + 1
+			if c == ':' && start < len(sqlStr) && sqlStr[start] == 'p' {
+				start++
+			}
+			end := start
+			for end < len(sqlStr) && sqlStr[end] >= '0' && sqlStr[end] <= '9' {
+				end++
+			}
+			if (c == ':' && start == i+1) || end == start || (i > 0 && isIdentifierByte(sqlStr[i-1])) {
+				break
+			}
+			index, _ := strconv.Atoi(sqlStr[start:end])
+			if index < 1 || index > len(args) {
+				return "", nil, fmt.Errorf("placeholder %s has no argument", sqlStr[i:end])
+			}
+			newArgs = append(newArgs, args[index-1])
+			sb.WriteByte('?')
+			numbered = true
+			i = end - 1
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	if !numbered {
+		return sqlStr, args, nil
+	}
+	if question {
+		return "", nil, fmt.Errorf("placeholder ? can not be mixed with numbered placeholders")
+	}
+	return sb.String(), newArgs, nil
+}
+
+// isIdentifierByte 是否为不需要转义的标识符中的字符
+func isIdentifierByte(c byte) bool {
+	return c == '_' || c == '$' || c == ':' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// walkSql 遍历 MySQL 格式的语句，字符串原样保留，反引号转义的标识符和 ? 占位符交给回调转换
+func walkSql(sqlStr string, identifier func(name string) string, bind func(index int) (string, error)) (string, error) {
+	var sb strings.Builder
+	index := 0
+	for i := 0; i < len(sqlStr); i++ {
+		c := sqlStr[i]
+		switch c {
+		case '\'', '"':
+			j := quotedEnd(sqlStr, i)
+			sb.WriteString(sqlStr[i : j+1])
+			i = j
+		case '`':
+			end := strings.IndexByte(sqlStr[i+1:], '`')
+			if end < 0 {
+				sb.WriteString(sqlStr[i:])
+				i = len(sqlStr)
+				continue
+			}
+			sb.WriteString(identifier(sqlStr[i+1 : i+1+end]))
+			i += end + 1
+		case '?':
+			index++
+			one, err := bind(index)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(one)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String(), nil
+}
+
+// quotedEnd 从 i 处的引号开始，返回字符串结束引号的位置，没有结束引号时为最后一个字符
+func quotedEnd(sqlStr string, i int) int {
+	c := sqlStr[i]
+	j := i + 1
+	for ; j < len(sqlStr); j++ {
+		if sqlStr[j] == '\\' {
+			j++
+			continue
+		}
+		if sqlStr[j] == c {
+			if j+1 < len(sqlStr) && sqlStr[j+1] == c {
+				j++
+				continue
+			}
+			break
+		}
+	}
+	if j >= len(sqlStr) {
+		j = len(sqlStr) - 1
+	}
+	return j
+}
+
+func placeholder(style PlaceholderStyle, index int) string {
+	switch style {
+	case PlaceholderDollar:
+		return "$" + strconv.Itoa(index)
+	case PlaceholderColon:
+		return ":p" + strconv.Itoa(index)
+	}
+	return "?"
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string {
+	return "mysql"
+}
+
+func (mysqlDialect) QuoteIdentifier(name string) string {
+	return quoteIdentifierParts([]string{name})
+}
+
+func (mysqlDialect) Placeholder(int) string {
+	return "?"
+}
+
+func (mysqlDialect) LimitOffset(offset, limit int) string {
+	return fmt.Sprintf("LIMIT %d, %d", offset, limit)
+}
+
+func (mysqlDialect) BoolLiteral(value bool) string {
+	if value {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// Upsert INSERT IGNORE / REPLACE INTO / ON DUPLICATE KEY UPDATE
+func (d mysqlDialect) Upsert(clause UpsertClause) (string, string, error) {
+	switch clause.Mode {
+	case UpsertIgnore:
+		return "INSERT IGNORE INTO", "", nil
+	case UpsertReplace:
+		return "REPLACE INTO", "", nil
+	case UpsertUpdate:
+	default:
+		return "", "", fmt.Errorf("upsert mode not support in %s: %s", d.Name(), clause.Mode)
+	}
+	if len(clause.Assignments) == 0 {
+		return "", "", fmt.Errorf("upsert has no column to update")
+	}
+	if clause.RowAlias != "" && !IsValidIdentifier(clause.RowAlias) {
+		return "", "", fmt.Errorf("invalid row alias: %s", clause.RowAlias)
+	}
+	inserted := func(column string) string {
+		if clause.RowAlias != "" {
+			return quoteIdentifierParts([]string{clause.RowAlias, column})
+		}
+		return fmt.Sprintf("VALUES(%s)", d.QuoteIdentifier(column))
+	}
+	setList := make([]string, 0, len(clause.Assignments))
+	for _, one := range clause.Assignments {
+		column := d.QuoteIdentifier(one.Column)
+		switch {
+		case one.Expr != "":
+			setList = append(setList, fmt.Sprintf("%s = %s", column, one.Expr))
+		case one.Increment:
+			setList = append(setList, fmt.Sprintf("%s = %s + %s", column, column, inserted(one.Column)))
+		default:
+			setList = append(setList, fmt.Sprintf("%s = %s", column, inserted(one.Column)))
+		}
+	}
+	suffix := " ON DUPLICATE KEY UPDATE " + strings.Join(setList, ", ")
+	if clause.RowAlias != "" {
+		suffix = fmt.Sprintf(" AS %s%s", d.QuoteIdentifier(clause.RowAlias), suffix)
+	}
+	return "INSERT INTO", suffix, nil
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string {
+	return "postgres"
+}
+
+func (postgresDialect) QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (postgresDialect) Placeholder(index int) string {
+	return placeholder(PlaceholderDollar, index)
+}
+
+func (postgresDialect) LimitOffset(offset, limit int) string {
+	return fmt.Sprintf("LIMIT %d OFFSET %d", limit, offset)
+}
+
+func (postgresDialect) BoolLiteral(value bool) string {
+	if value {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// Upsert ON CONFLICT DO NOTHING / DO UPDATE，不支持 REPLACE
+func (d postgresDialect) Upsert(clause UpsertClause) (string, string, error) {
+	if clause.Mode == UpsertReplace {
+		return "", "", fmt.Errorf("upsert mode not support in %s: %s", d.Name(), clause.Mode)
+	}
+	return onConflictUpsert(d, clause)
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string {
+	return "sqlite"
+}
+
+func (sqliteDialect) QuoteIdentifier(name string) string {
+	return postgresDialect{}.QuoteIdentifier(name)
+}
+
+func (sqliteDialect) Placeholder(index int) string {
+	return placeholder(PlaceholderQuestion, index)
+}
+
+func (sqliteDialect) LimitOffset(offset, limit int) string {
+	return postgresDialect{}.LimitOffset(offset, limit)
+}
+
+func (sqliteDialect) BoolLiteral(value bool) string {
+	if value {
+		return "1"
+	}
+	return "0"
+}
+
+// Upsert ON CONFLICT DO NOTHING / DO UPDATE，REPLACE 使用 REPLACE INTO
+func (d sqliteDialect) Upsert(clause UpsertClause) (string, string, error) {
+	if clause.Mode == UpsertReplace {
+		return "REPLACE INTO", "", nil
+	}
+	return onConflictUpsert(d, clause)
+}
+
+// onConflictUpsert PostgreSQL 和 SQLite 的 ON CONFLICT 语句，更新时必须指定冲突判断的字段
+func onConflictUpsert(d Dialect, clause UpsertClause) (string, string, error) {
+	target := ""
+	if len(clause.ConflictColumns) > 0 {
+		columns := make([]string, 0, len(clause.ConflictColumns))
+		for _, one := range clause.ConflictColumns {
+			columns = append(columns, d.QuoteIdentifier(one))
+		}
+		target = " (" + strings.Join(columns, ", ") + ")"
+	}
+	switch clause.Mode {
+	case UpsertIgnore:
+		return "INSERT INTO", " ON CONFLICT" + target + " DO NOTHING", nil
+	case UpsertUpdate:
+	default:
+		return "", "", fmt.Errorf("upsert mode not support in %s: %s", d.Name(), clause.Mode)
+	}
+	if target == "" {
+		return "", "", fmt.Errorf("upsert conflict columns is required in %s", d.Name())
+	}
+	if len(clause.Assignments) == 0 {
+		return "", "", fmt.Errorf("upsert has no column to update")
+	}
+
+	table := clause.Table
+	if index := strings.LastIndex(table, "."); index >= 0 {
+		table = table[index+1:]
+	}
+	setList := make([]string, 0, len(clause.Assignments))
+	for _, one := range clause.Assignments {
+		column := d.QuoteIdentifier(one.Column)
+		excluded := "EXCLUDED." + column
+		switch {
+		case one.Expr != "":
+			setList = append(setList, fmt.Sprintf("%s = %s", column, one.Expr))
+		case one.Increment:
+			setList = append(setList, fmt.Sprintf("%s = %s.%s + %s", column, d.QuoteIdentifier(table), column, excluded))
+		default:
+			setList = append(setList, fmt.Sprintf("%s = %s", column, excluded))
+		}
+	}
+	return "INSERT INTO", " ON CONFLICT" + target + " DO UPDATE SET " + strings.Join(setList, ", "), nil
+}
+
+// placeholderDialect 替换占位符格式的方言
+type placeholderDialect struct {
+	Dialect
+	style PlaceholderStyle
+}
+
+func (d placeholderDialect) Placeholder(index int) string {
+	return placeholder(d.style, index)
+}
@@ -0,0 +1,114 @@
+package sqlstatement
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Expr 原生的sql表达式及其绑定的参数，如 DATE(created_at) = ?，sql需由调用方保证安全
+type Expr struct {
+	Sql  string
+	Args []any
+}
+
+// Not 对条件取反，可以包裹 Condition、LogicCondition、Exists、Expr 等任意条件
+type Not struct {
+	Condition any
+}
+
+// NewExpr 新建一个表达式
+func NewExpr(sqlStr string, args ...any) Expr {
+	return Expr{Sql: sqlStr, Args: args}
+}
+
+// build 生成表达式，检查占位符的数量和参数是否一致
+func (e Expr) build() (string, []any, error) {
+	sqlStr := strings.TrimSpace(e.Sql)
+	if sqlStr == "" {
+		return "", []any{}, fmt.Errorf("expr is empty")
+	}
+	if num := countPlaceholders(sqlStr); num != len(e.Args) {
+		return "", []any{}, fmt.Errorf("expr %s has %d placeholders but %d args", sqlStr, num, len(e.Args))
+	}
+	args := make([]any, 0, len(e.Args))
+	args = append(args, e.Args...)
+	return sqlStr, args, nil
+}
+
+// countPlaceholders 统计sql中 ? 占位符的数量，忽略引号中的内容
+func countPlaceholders(sqlStr string) int {
+	num := 0
+	var quote rune
+	escaped := false
+	for _, r := range sqlStr {
+		if quote != 0 {
+			if escaped {
+				escaped = false
+			} else if r == '\\' && quote != '`' {
+				escaped = true
+			} else if r == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch r {
+		case '\'', '"', '`':
+			quote = r
+		case '?':
+			num++
+		}
+	}
+	return num
+}
+
+// UpdateExpr 更新语句中的赋值表达式，作为 updateMap 的值使用，如 SET stock = stock - ?
+// 任意表达式可以直接使用 Expr，如 SET updated_at = NOW()
+type UpdateExpr struct {
+	op    string
+	value any
+}
+
+// Incr 字段加上 value，SET column = column + ?
+func Incr(value any) UpdateExpr {
+	return UpdateExpr{op: "+", value: value}
+}
+
+// Decr 字段减去 value，SET column = column - ?
+func Decr(value any) UpdateExpr {
+	return UpdateExpr{op: "-", value: value}
+}
+
+// Default 字段设置为默认值，SET column = DEFAULT，SQLite 不支持
+func Default() UpdateExpr {
+	return UpdateExpr{op: "DEFAULT"}
+}
+
+// Null 字段设置为 NULL，SET column = NULL
+func Null() UpdateExpr {
+	return UpdateExpr{op: "NULL"}
+}
+
+// assignmentValue 生成 SET 中一个字段等号右边的语句，column 为已转义的字段名，普通的值使用 ? 占位符
+func assignmentValue(d Dialect, column string, value any) (string, []any, error) {
+	switch v := value.(type) {
+	case Expr:
+		return v.build()
+	case UpdateExpr:
+		switch v.op {
+		case "+", "-":
+			if isNilValue(v.value) {
+				return "", nil, fmt.Errorf("update %s %s value is nil", column, v.op)
+			}
+			return fmt.Sprintf("%s %s ?", column, v.op), []any{v.value}, nil
+		case "DEFAULT":
+			if d.Name() == DialectSQLite.Name() {
+				return "", nil, fmt.Errorf("update %s to DEFAULT not support in %s", column, d.Name())
+			}
+			return v.op, []any{}, nil
+		case "NULL":
+			return v.op, []any{}, nil
+		}
+		return "", nil, fmt.Errorf("update expr is invalid: %s", column)
+	}
+	return "?", []any{value}, nil
+}
@@ -0,0 +1,27 @@
+package sqlstatement
+
+import (
+	"errors"
+)
+
+// ErrFullTableWrite 没有where条件的更新或删除，需显式设置允许全表操作
+var ErrFullTableWrite = errors.New("update or delete without where clause is not allowed")
+
+// SetStatementAllowFullTable 设置是否允许没有where条件的更新和删除，默认不允许
+func SetStatementAllowFullTable(allow bool) StatementOption {
+	return func(s *Statement) {
+		s.allowFullTable = allow
+	}
+}
+
+// fullTableWhere 生成更新或删除的where语句，条件为空且不允许全表操作时返回 ErrFullTableWrite
+func (s *Statement) fullTableWhere(group LogicCondition, allColumns []string) (string, []any, error) {
+	sqlStr, dataList, err := s.whereByColumns(group, allColumns, true)
+	if err != nil {
+		return "", nil, err
+	}
+	if sqlStr == "" && !s.allowFullTable {
+		return "", nil, ErrFullTableWrite
+	}
+	return sqlStr, dataList, nil
+}
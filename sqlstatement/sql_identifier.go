@@ -0,0 +1,114 @@
+package sqlstatement
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	identifierQuote     = "`"
+	identifierMaxLength = 64 // mysql 标识符的最大长度
+	identifierMaxParts  = 3  // db.table.column
+)
+
+// IsValidIdentifier 判断是否为合法的mysql标识符（不含.的单个部分）
+// 允许的字符为 [0-9a-zA-Z$_] 以及 U+0080 .. U+FFFF
+func IsValidIdentifier(part string) bool {
+	if part == "" || utf8.RuneCountInString(part) > identifierMaxLength {
+		return false
+	}
+	for _, r := range part {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '$':
+		case r >= 0x80 && r <= 0xFFFF && r != utf8.RuneError:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// SplitIdentifier 拆分限定名，如 db.table.col 或 `t`.`col`，并校验每一部分
+// 最后一部分允许为 *，如 t.*
+func SplitIdentifier(name string) ([]string, error) {
+	name = strings.TrimSpace(strings.ReplaceAll(name, identifierQuote, ""))
+	if name == "" {
+		return nil, fmt.Errorf("identifier is empty")
+	}
+	parts := strings.Split(name, ".")
+	if len(parts) > identifierMaxParts {
+		return nil, fmt.Errorf("identifier has too many parts: %s", name)
+	}
+	for i, part := range parts {
+		if part == "*" && i == len(parts)-1 && len(parts) > 1 {
+			continue
+		}
+		if !IsValidIdentifier(part) {
+			return nil, fmt.Errorf("invalid identifier: %s", name)
+		}
+	}
+	return parts, nil
+}
+
+// QuoteIdentifier 转义标识符，限定名的每一部分单独转义，如 t.user_id 转为 `t`.`user_id`
+func QuoteIdentifier(name string) (string, error) {
+	parts, err := SplitIdentifier(name)
+	if err != nil {
+		return "", err
+	}
+	return quoteIdentifierParts(parts), nil
+}
+
+func quoteIdentifierParts(parts []string) string {
+	quoted := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part == "*" {
+			quoted = append(quoted, part)
+			continue
+		}
+		quoted = append(quoted, identifierQuote+part+identifierQuote)
+	}
+	return strings.Join(quoted, ".")
+}
+
+// SplitAlias 拆分带别名的标识符，支持 "t.user_id AS uid" 和 "t.user_id uid" 两种写法
+func SplitAlias(expr string) (name string, alias string, err error) {
+	fields := strings.Fields(expr)
+	switch {
+	case len(fields) == 1:
+		name = fields[0]
+	case len(fields) == 2:
+		name, alias = fields[0], fields[1]
+	case len(fields) == 3 && strings.EqualFold(fields[1], "AS"):
+		name, alias = fields[0], fields[2]
+	default:
+		return "", "", fmt.Errorf("invalid identifier: %s", expr)
+	}
+	if _, err = SplitIdentifier(name); err != nil {
+		return "", "", err
+	}
+	if alias != "" {
+		alias = strings.ReplaceAll(alias, identifierQuote, "")
+		if !IsValidIdentifier(alias) {
+			return "", "", fmt.Errorf("invalid alias: %s", expr)
+		}
+	}
+	return name, alias, nil
+}
+
+// QuoteIdentifierWithAlias 转义带别名的标识符，如 "t.user_id AS uid" 转为 `t`.`user_id` AS `uid`
+func QuoteIdentifierWithAlias(expr string) (string, error) {
+	name, alias, err := SplitAlias(expr)
+	if err != nil {
+		return "", err
+	}
+	quoted, err := QuoteIdentifier(name)
+	if err != nil {
+		return "", err
+	}
+	if alias == "" {
+		return quoted, nil
+	}
+	return fmt.Sprintf("%s AS %s%s%s", quoted, identifierQuote, alias, identifierQuote), nil
+}
@@ -0,0 +1,150 @@
+package sqlstatement
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// InterpolateForLog 将参数按 MySQL 的字面量格式替换到 ? 占位符中，仅用于日志和调试，不能用于执行
+// 字符串按默认的 sql_mode（未开启 NO_BACKSLASH_ESCAPES）转义，时间使用值自身的时区
+// 参数数量与占位符不一致，或有无法准确表示的值（如结构体、map、非法utf8字符串、NaN）时返回错误
+func InterpolateForLog(sqlStr string, args ...any) (string, error) {
+	return InterpolateForLogDialect(DialectMySQL, sqlStr, args...)
+}
+
+// InterpolateForLogDialect 与 InterpolateForLog 相同，按方言的字面量格式替换，支持 $n、:pN 占位符
+// 非 MySQL 方言的字符串只将单引号转为两个单引号，布尔值使用方言的 BoolLiteral
+func InterpolateForLogDialect(d Dialect, sqlStr string, args ...any) (string, error) {
+	sqlStr, args, err := unbindSql(sqlStr, args)
+	if err != nil {
+		return "", fmt.Errorf("interpolate: %w", err)
+	}
+	count := 0
+	ret, err := walkSql(sqlStr, func(name string) string {
+		return identifierQuote + name + identifierQuote
+	}, func(index int) (string, error) {
+		count = index
+		if index > len(args) {
+			return "", fmt.Errorf("interpolate: placeholder %d has no argument", index)
+		}
+		literal, err := sqlLiteral(d, args[index-1])
+		if err != nil {
+			return "", fmt.Errorf("interpolate: argument %d: %w", index, err)
+		}
+		return literal, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if count != len(args) {
+		return "", fmt.Errorf("interpolate: %d placeholders but %d arguments", count, len(args))
+	}
+	return ret, nil
+}
+
+// sqlLiteral 将单个值转为方言的字面量
+func sqlLiteral(d Dialect, value any) (string, error) {
+	if valuer, ok := value.(driver.Valuer); ok {
+		if isNilValue(value) {
+			return "NULL", nil
+		}
+		v, err := valuer.Value()
+		if err != nil {
+			return "", err
+		}
+		if _, ok = v.(driver.Valuer); ok {
+			return "", fmt.Errorf("value type not support: %T", value)
+		}
+		return sqlLiteral(d, v)
+	}
+	isMySQL := d.Name() == DialectMySQL.Name()
+	switch v := value.(type) {
+	case nil:
+		return "NULL", nil
+	case time.Time:
+		if v.IsZero() {
+			return "'0000-00-00'", nil
+		}
+		return "'" + v.Format("2006-01-02 15:04:05.999999") + "'", nil
+	case []byte:
+		if v == nil {
+			return "NULL", nil
+		}
+		if d.Name() == DialectPostgreSQL.Name() {
+			return "'\\x" + hex.EncodeToString(v) + "'", nil
+		}
+		return "X'" + hex.EncodeToString(v) + "'", nil
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return "NULL", nil
+		}
+		return sqlLiteral(d, rv.Elem().Interface())
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", fmt.Errorf("float value not support: %v", f)
+		}
+		return strconv.FormatFloat(f, 'g', -1, rv.Type().Bits()), nil
+	case reflect.Bool:
+		return d.BoolLiteral(rv.Bool()), nil
+	case reflect.String:
+		if !utf8.ValidString(rv.String()) {
+			return "", fmt.Errorf("string value is not valid utf8")
+		}
+		if isMySQL {
+			return "'" + escapeStringLiteral(rv.String()) + "'", nil
+		}
+		if strings.IndexByte(rv.String(), 0) >= 0 {
+			return "", fmt.Errorf("string value contains NUL")
+		}
+		return "'" + strings.ReplaceAll(rv.String(), "'", "''") + "'", nil
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return sqlLiteral(d, rv.Bytes())
+		}
+	}
+	return "", fmt.Errorf("value type not support: %T", value)
+}
+
+// escapeStringLiteral 与 mysql 驱动 escapeStringBackslash 的转义规则一致
+func escapeStringLiteral(str string) string {
+	var sb strings.Builder
+	sb.Grow(len(str))
+	for i := 0; i < len(str); i++ {
+		switch c := str[i]; c {
+		case 0:
+			sb.WriteString(`\0`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\x1a':
+			sb.WriteString(`\Z`)
+		case '\'':
+			sb.WriteString(`\'`)
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
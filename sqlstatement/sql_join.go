@@ -0,0 +1,140 @@
+package sqlstatement
+
+import (
+	"fmt"
+	"github.com/samber/lo"
+	"strings"
+)
+
+// Column 表示条件中引用的列而不是绑定的值，如 ON 条件中的 o.user_id = u.id
+type Column string
+
+// Join 关联查询
+type Join struct {
+	Type    string         // INNER / LEFT / RIGHT，默认为 INNER
+	Table   string         // 关联的表名
+	Alias   string         // 表别名，为空则使用表名
+	Columns []string       // 关联表的字段，条件中需用 别名.字段 的方式引用
+	On      LogicCondition // 关联条件，引用其他列时值使用 Column
+}
+
+var joinTypeList = []string{"INNER", "LEFT", "RIGHT"}
+
+// SetTableAlias 设置主表的别名
+func SetTableAlias(alias string) SelectOption {
+	return func(c *selectConfig) {
+		c.tableAlias = alias
+	}
+}
+
+// SetJoin 设置关联查询，可多次调用，按顺序追加
+func SetJoin(joins ...Join) SelectOption {
+	return func(c *selectConfig) {
+		c.joins = append(c.joins, joins...)
+	}
+}
+
+// qualifier 关联表在条件中的引用名
+func (j Join) qualifier() string {
+	if j.Alias != "" {
+		return j.Alias
+	}
+	return j.Table
+}
+
+// qualifyColumns 为字段添加表的限定名，并添加 qualifier.* 用于查询所有字段
+func qualifyColumns(qualifier string, columns []string) []string {
+	qualifier = strings.ReplaceAll(qualifier, identifierQuote, "")
+	ret := make([]string, 0, len(columns)+1)
+	for _, one := range columns {
+		ret = append(ret, qualifier+"."+strings.ReplaceAll(one, identifierQuote, ""))
+	}
+	return append(ret, qualifier+".*")
+}
+
+// allowedColumns 允许使用的字段，主表字段可以直接使用或使用 表名.字段（有别名时为 别名.字段），关联表字段必须使用 别名.字段
+func (c *selectConfig) allowedColumns(tableName string, allColumns []string) []string {
+	allowed := new(Statement).buildFieldNames(allColumns)
+	qualifier := c.tableAlias
+	if qualifier == "" {
+		qualifier = tableName
+	}
+	allowed = append(allowed, qualifyColumns(qualifier, allColumns)...)
+	for _, one := range c.joins {
+		allowed = append(allowed, qualifyColumns(one.qualifier(), one.Columns)...)
+	}
+	return allowed
+}
+
+// quoteTableWithAlias 转义表名和别名
+func quoteTableWithAlias(tableName string, alias string) (string, error) {
+	if alias == "" {
+		return QuoteIdentifier(tableName)
+	}
+	return QuoteIdentifierWithAlias(tableName + " AS " + alias)
+}
+
+// joinClause 生成好的关联语句
+type joinClause struct {
+	sql  string
+	args []any
+}
+
+// buildJoins 生成关联语句，allowed 为 ON 条件中允许使用的字段，未知字段按 st 的 columnPolicy 处理
+func (c *selectConfig) buildJoins(st *Statement, allowed []string, strict bool) ([]joinClause, error) {
+	ret := make([]joinClause, 0, len(c.joins))
+	for _, one := range c.joins {
+		joinType := normalizeOperator(one.Type)
+		if joinType == "" {
+			joinType = "INNER"
+		}
+		if !lo.Contains(joinTypeList, joinType) {
+			return nil, fmt.Errorf("join type not support: %s", one.Type)
+		}
+		table, err := quoteTableWithAlias(one.Table, one.Alias)
+		if err != nil {
+			return nil, err
+		}
+
+		on, _, err := st.filterRelationCondition(one.On, allowed)
+		if err != nil {
+			return nil, err
+		}
+		var onStr string
+		var onList []any
+		if strict {
+			onStr, onList, err = st.GenerateWhereClauseStrict(on, allowed...)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			onStr, onList = st.GenerateWhereClause(on)
+		}
+		if onStr == "" {
+			return nil, fmt.Errorf("join %s on condition is empty", one.Table)
+		}
+		ret = append(ret, joinClause{
+			sql:  fmt.Sprintf("%s JOIN %s ON %s", joinType, table, onStr),
+			args: onList,
+		})
+	}
+	return ret, nil
+}
+
+// buildFrom 生成 FROM 之后的表名和关联语句
+func (c *selectConfig) buildFrom(st *Statement, tableName string, allowed []string, strict bool) (string, []any, error) {
+	from, err := quoteTableWithAlias(tableName, c.tableAlias)
+	if err != nil {
+		return "", nil, err
+	}
+	joins, err := c.buildJoins(st, allowed, strict)
+	if err != nil {
+		return "", nil, err
+	}
+	dataList := make([]any, 0)
+	for _, one := range joins {
+		from = fmt.Sprintf("%s %s", from, one.sql)
+		dataList = append(dataList, one.args...)
+	}
+	return from, dataList, nil
+}
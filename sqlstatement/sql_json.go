@@ -0,0 +1,221 @@
+package sqlstatement
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// jsonNode 条件树的json格式，一个节点只能是以下三种之一：
+// 条件组 {"operator":"AND","conditions":[...]}
+// 单个条件 {"field":"age","operator":">","value":18}
+// 取反 {"not":{...}}
+type jsonNode struct {
+	Field      string            `json:"field,omitempty"`
+	Operator   string            `json:"operator,omitempty"`
+	Value      json.RawMessage   `json:"value,omitempty"`
+	Conditions []json.RawMessage `json:"conditions,omitempty"`
+	Not        json.RawMessage   `json:"not,omitempty"`
+}
+
+// MarshalJSON 转为json格式的条件树，Expr、Exists、SubQuery、Column 不能序列化
+func (l LogicCondition) MarshalJSON() ([]byte, error) {
+	node, err := marshalLogicCondition(l)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(node)
+}
+
+// UnmarshalJSON 解析json格式的条件树，会检查字段名和操作符是否有效
+// 字段是否在允许的列中需使用 ParseFilterJSON
+func (l *LogicCondition) UnmarshalJSON(data []byte) error {
+	node := jsonNode{}
+	if err := unmarshalJsonNode(data, &node); err != nil {
+		return err
+	}
+	if node.Field != "" || len(node.Not) > 0 {
+		return fmt.Errorf("logic condition must have conditions")
+	}
+	group, err := unmarshalLogicCondition(node)
+	if err != nil {
+		return err
+	}
+	*l = group
+	return nil
+}
+
+// ParseFilterJSON 解析前端传入的json过滤条件，字段必须在 allowedColumns 中，可直接用于 GenerateWhereClause
+func ParseFilterJSON(data []byte, allowedColumns ...string) (LogicCondition, error) {
+	if len(allowedColumns) == 0 {
+		return LogicCondition{}, fmt.Errorf("allowed columns is empty")
+	}
+	group := LogicCondition{}
+	if err := json.Unmarshal(data, &group); err != nil {
+		return LogicCondition{}, err
+	}
+	if _, _, err := new(Statement).GenerateWhereClauseStrict(group, allowedColumns...); err != nil {
+		return LogicCondition{}, err
+	}
+	return group, nil
+}
+
+// unmarshalJsonNode 解析单个节点，不允许未知的键
+func unmarshalJsonNode(data []byte, node *jsonNode) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(node)
+}
+
+func marshalLogicCondition(l LogicCondition) (jsonNode, error) {
+	node := jsonNode{Operator: strings.ToUpper(l.Operator), Conditions: make([]json.RawMessage, 0, len(l.Conditions))}
+	if node.Operator == "" {
+		node.Operator = defaultLogicOperator
+	}
+	for _, one := range l.Conditions {
+		child, err := marshalNode(one)
+		if err != nil {
+			return jsonNode{}, err
+		}
+		data, err := json.Marshal(child)
+		if err != nil {
+			return jsonNode{}, err
+		}
+		node.Conditions = append(node.Conditions, data)
+	}
+	return node, nil
+}
+
+func marshalNode(one any) (jsonNode, error) {
+	switch c := one.(type) {
+	case Condition:
+		value := c.Value
+		switch v := c.Value.(type) {
+		case Range:
+			value = []any{v.Start, v.End}
+		case *Range:
+			value = []any{v.Start, v.End}
+		case Column, SubQuery:
+			return jsonNode{}, fmt.Errorf("condition value type not support in json: %T", c.Value)
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return jsonNode{}, err
+		}
+		operator := normalizeOperator(c.Operator)
+		if operator == "" {
+			operator = defaultMapOperator
+		}
+		return jsonNode{Field: c.Field, Operator: operator, Value: data}, nil
+	case LogicCondition:
+		return marshalLogicCondition(c)
+	case Not:
+		child, err := marshalNode(c.Condition)
+		if err != nil {
+			return jsonNode{}, err
+		}
+		data, err := json.Marshal(child)
+		if err != nil {
+			return jsonNode{}, err
+		}
+		return jsonNode{Not: data}, nil
+	}
+	return jsonNode{}, fmt.Errorf("condition type not support in json: %T", one)
+}
+
+func unmarshalLogicCondition(node jsonNode) (LogicCondition, error) {
+	operator := strings.ToUpper(node.Operator)
+	if operator == "" {
+		operator = defaultLogicOperator
+	}
+	if operator != "AND" && operator != "OR" {
+		return LogicCondition{}, fmt.Errorf("logic operator not support: %s", node.Operator)
+	}
+	group := LogicCondition{Operator: operator, Conditions: make([]any, 0, len(node.Conditions))}
+	for _, data := range node.Conditions {
+		one, err := unmarshalNode(data)
+		if err != nil {
+			return LogicCondition{}, err
+		}
+		group.Conditions = append(group.Conditions, one)
+	}
+	return group, nil
+}
+
+func unmarshalNode(data []byte) (any, error) {
+	node := jsonNode{}
+	if err := unmarshalJsonNode(data, &node); err != nil {
+		return nil, err
+	}
+	switch {
+	case len(node.Not) > 0:
+		if node.Field != "" || node.Conditions != nil {
+			return nil, fmt.Errorf("not condition can not have other keys")
+		}
+		child, err := unmarshalNode(node.Not)
+		if err != nil {
+			return nil, err
+		}
+		return Not{Condition: child}, nil
+	case node.Field != "":
+		if node.Conditions != nil {
+			return nil, fmt.Errorf("condition %s can not have conditions", node.Field)
+		}
+		return unmarshalCondition(node)
+	}
+	return unmarshalLogicCondition(node)
+}
+
+func unmarshalCondition(node jsonNode) (Condition, error) {
+	if _, err := SplitIdentifier(node.Field); err != nil {
+		return Condition{}, err
+	}
+	operator := normalizeOperator(node.Operator)
+	if operator == "" {
+		operator = defaultMapOperator
+	}
+	if !IsSupportedOperator(operator) {
+		return Condition{}, fmt.Errorf("operator not support: %s", node.Operator)
+	}
+	var value any
+	if len(node.Value) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(node.Value))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return Condition{}, err
+		}
+	}
+	value, err := normalizeJsonValue(value)
+	if err != nil {
+		return Condition{}, fmt.Errorf("condition %s value: %w", node.Field, err)
+	}
+	return Condition{Field: node.Field, Operator: operator, Value: value}, nil
+}
+
+// normalizeJsonValue 数字转为 int64 或 float64，只允许标量和标量的数组
+func normalizeJsonValue(value any) (any, error) {
+	switch v := value.(type) {
+	case nil, string, bool:
+		return v, nil
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+		return v.Float64()
+	case []any:
+		list := make([]any, 0, len(v))
+		for _, one := range v {
+			if _, ok := one.([]any); ok {
+				return nil, fmt.Errorf("nested array not support")
+			}
+			item, err := normalizeJsonValue(one)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("value type not support: %T", value)
+}
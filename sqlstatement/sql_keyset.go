@@ -0,0 +1,241 @@
+package sqlstatement
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/samber/lo"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KeysetPage 游标分页参数
+type KeysetPage struct {
+	SortKeys []OrderBy // 排序字段，最后一个字段需要唯一（如主键），否则翻页可能会遗漏数据
+	Cursor   string    // 上一页最后一行生成的游标，为空表示第一页
+	Limit    int       // 每页数量，可多查一条用于判断是否还有下一页
+}
+
+// keysetCursor 游标的内容，Keys 用于校验游标与排序字段是否一致
+type keysetCursor struct {
+	Keys   []string      `json:"k"`
+	Values []cursorValue `json:"v"`
+}
+
+// cursorValue 带类型的游标值，避免json解析后整数变为浮点数
+type cursorValue struct {
+	Type  string `json:"t"`
+	Value string `json:"v"`
+}
+
+// cursorKeys 游标中记录的排序字段，降序的字段以 - 开头
+func cursorKeys(sortKeys []OrderBy) []string {
+	keys := make([]string, 0, len(sortKeys))
+	for _, one := range sortKeys {
+		key := strings.ReplaceAll(strings.TrimSpace(one.Field), identifierQuote, "")
+		if one.Desc {
+			key = "-" + key
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// encodeCursorValue 将值转为带类型的字符串
+func encodeCursorValue(value any) (cursorValue, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return cursorValue{Type: "t", Value: v.Format(time.RFC3339Nano)}, nil
+	case *time.Time:
+		if v != nil {
+			return cursorValue{Type: "t", Value: v.Format(time.RFC3339Nano)}, nil
+		}
+	case []byte:
+		return cursorValue{Type: "x", Value: base64.StdEncoding.EncodeToString(v)}, nil
+	}
+	if isNilValue(value) {
+		return cursorValue{}, fmt.Errorf("cursor value can not be nil")
+	}
+	rv := reflect.Indirect(reflect.ValueOf(value))
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cursorValue{Type: "i", Value: strconv.FormatInt(rv.Int(), 10)}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cursorValue{Type: "u", Value: strconv.FormatUint(rv.Uint(), 10)}, nil
+	case reflect.Float32, reflect.Float64:
+		return cursorValue{Type: "f", Value: strconv.FormatFloat(rv.Float(), 'g', -1, 64)}, nil
+	case reflect.String:
+		return cursorValue{Type: "s", Value: rv.String()}, nil
+	case reflect.Bool:
+		return cursorValue{Type: "b", Value: strconv.FormatBool(rv.Bool())}, nil
+	}
+	return cursorValue{}, fmt.Errorf("cursor value type not support: %T", value)
+}
+
+// decode 还原为原始类型的值
+func (c cursorValue) decode() (any, error) {
+	switch c.Type {
+	case "i":
+		return strconv.ParseInt(c.Value, 10, 64)
+	case "u":
+		return strconv.ParseUint(c.Value, 10, 64)
+	case "f":
+		return strconv.ParseFloat(c.Value, 64)
+	case "s":
+		return c.Value, nil
+	case "b":
+		return strconv.ParseBool(c.Value)
+	case "t":
+		return time.Parse(time.RFC3339Nano, c.Value)
+	case "x":
+		return base64.StdEncoding.DecodeString(c.Value)
+	}
+	return nil, fmt.Errorf("cursor value type not support: %s", c.Type)
+}
+
+// getRowValue 获取行中排序字段的值，找不到 别名.字段 时使用 字段
+func getRowValue(row map[string]any, field string) (any, bool) {
+	field = strings.ReplaceAll(strings.TrimSpace(field), identifierQuote, "")
+	if val, ok := row[field]; ok {
+		return val, true
+	}
+	if index := strings.LastIndex(field, "."); index >= 0 {
+		val, ok := row[field[index+1:]]
+		return val, ok
+	}
+	return nil, false
+}
+
+// EncodeCursor 通过上一页的最后一行生成不透明的游标，可直接返回给前端
+func EncodeCursor(sortKeys []OrderBy, lastRow map[string]any) (string, error) {
+	if len(sortKeys) == 0 {
+		return "", fmt.Errorf("sort keys is empty")
+	}
+	cursor := keysetCursor{Keys: cursorKeys(sortKeys)}
+	for _, one := range sortKeys {
+		val, ok := getRowValue(lastRow, one.Field)
+		if !ok {
+			return "", fmt.Errorf("last row has no field: %s", one.Field)
+		}
+		value, err := encodeCursorValue(val)
+		if err != nil {
+			return "", err
+		}
+		cursor.Values = append(cursor.Values, value)
+	}
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor 解析游标，返回与排序字段对应的值，排序字段与生成游标时不一致时返回错误
+func DecodeCursor(token string, sortKeys []OrderBy) ([]any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	cursor := keysetCursor{}
+	if err = json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	keys := cursorKeys(sortKeys)
+	if strings.Join(cursor.Keys, ",") != strings.Join(keys, ",") || len(cursor.Values) != len(keys) {
+		return nil, fmt.Errorf("cursor not match sort keys: %s", strings.Join(keys, ","))
+	}
+	values := make([]any, 0, len(cursor.Values))
+	for _, one := range cursor.Values {
+		val, err := one.decode()
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %w", err)
+		}
+		values = append(values, val)
+	}
+	return values, nil
+}
+
+// buildKeysetExpr 生成游标之后的条件，排序方向一致时使用 (a, b) > (?, ?)，否则展开为 OR 条件
+func buildKeysetExpr(fields []string, sortKeys []OrderBy, values []any) Expr {
+	compare := func(one OrderBy) string {
+		if one.Desc {
+			return "<"
+		}
+		return ">"
+	}
+
+	sameDirection := lo.EveryBy(sortKeys, func(item OrderBy) bool {
+		return item.Desc == sortKeys[0].Desc
+	})
+	if sameDirection {
+		if len(fields) == 1 {
+			return NewExpr(fmt.Sprintf("%s %s ?", fields[0], compare(sortKeys[0])), values...)
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(fields)), ", ")
+		return NewExpr(fmt.Sprintf("(%s) %s (%s)", strings.Join(fields, ", "), compare(sortKeys[0]), placeholders), values...)
+	}
+
+	orList := make([]string, 0, len(fields))
+	args := make([]any, 0)
+	for i := range fields {
+		andList := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			andList = append(andList, fmt.Sprintf("%s = ?", fields[j]))
+			args = append(args, values[j])
+		}
+		andList = append(andList, fmt.Sprintf("%s %s ?", fields[i], compare(sortKeys[i])))
+		args = append(args, values[i])
+		if i == 0 {
+			orList = append(orList, andList[0])
+			continue
+		}
+		orList = append(orList, "("+strings.Join(andList, " AND ")+")")
+	}
+	return NewExpr(strings.Join(orList, " OR "), args...)
+}
+
+// KeysetSelectSql 游标分页的查询语句，通过上一页最后一行的排序字段值定位，避免大偏移量的 LIMIT offset, n
+func (s *Statement) KeysetSelectSql(tableName string, allColumns []string, selectStr string, whereCondition LogicCondition, page KeysetPage, opts ...SelectOption) (string, []any) {
+	if len(page.SortKeys) == 0 {
+		return "", []any{}
+	}
+	allColumns = s.buildFieldNames(allColumns)
+	allowed := newSelectConfig(opts...).allowedColumns(tableName, allColumns)
+
+	fields := make([]string, 0, len(page.SortKeys))
+	for _, one := range page.SortKeys {
+		if lo.IndexOf(allowed, s.buildOneFieldName(strings.TrimSpace(one.Field))) < 0 {
+			return "", []any{}
+		}
+		field, err := QuoteIdentifier(one.Field)
+		if err != nil {
+			return "", []any{}
+		}
+		fields = append(fields, field)
+	}
+
+	whereCondition, _, err := s.filterCondition(whereCondition, allowed)
+	if err != nil {
+		return "", []any{}
+	}
+	if page.Cursor != "" {
+		values, err := DecodeCursor(page.Cursor, page.SortKeys)
+		if err != nil {
+			return "", []any{}
+		}
+		whereCondition = appendConditions(whereCondition, buildKeysetExpr(fields, page.SortKeys, values))
+	}
+
+	whereStr, whereDataList := s.GenerateWhereClause(whereCondition)
+	opts = append([]SelectOption{SetOrderBy(page.SortKeys...)}, opts...)
+	query, dataList, err := s.selectSql(tableName, allColumns, selectStr, whereStr, whereDataList, 0, 0, opts...)
+	if err != nil {
+		return "", []any{}
+	}
+	if page.Limit > 0 {
+		query = fmt.Sprintf("%s LIMIT %d", query, page.Limit)
+	}
+	return s.rebind(query, dataList)
+}
@@ -0,0 +1,245 @@
+package sqlstatement
+
+import (
+	"fmt"
+	"github.com/samber/lo"
+	"github.com/tianlin0/go-plat-utils/cond"
+	"github.com/tianlin0/go-plat-utils/conv"
+	"github.com/tianlin0/go-plat-utils/utils"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// OperatorBuilder 生成单个条件的sql，field 为已经转义过的列名，operator 为规范化后的操作符
+type OperatorBuilder func(field string, operator string, value any) (string, []any, error)
+
+// Operator 操作符定义
+type Operator struct {
+	Name      string          // 操作符名称，如 NOT LIKE
+	ListValue bool            // 是否接收数组类型的值，为false时 = 和 != 的数组值会被转为 IN / NOT IN 查询，其他返回错误
+	NoValue   bool            // 是否不需要值，如 IS NULL
+	Builder   OperatorBuilder // sql生成方法
+}
+
+var operatorRegistry = struct {
+	sync.RWMutex
+	operators map[string]Operator
+}{
+	operators: make(map[string]Operator),
+}
+
+func init() {
+	for _, one := range []string{"=", "!=", "<>", ">=", ">", "<=", "<",
+		"LIKE", "NOT LIKE", "LIKE BINARY", "NOT LIKE BINARY", "REGEXP", "NOT REGEXP"} {
+		_ = RegisterOperator(Operator{Name: one, Builder: buildCompareOperator})
+	}
+	for _, one := range []string{"IN", "NOT IN"} {
+		_ = RegisterOperator(Operator{Name: one, ListValue: true, Builder: buildInOperator})
+	}
+	for _, one := range []string{"BETWEEN", "NOT BETWEEN"} {
+		_ = RegisterOperator(Operator{Name: one, ListValue: true, Builder: buildBetweenOperator})
+	}
+	for _, one := range []string{"IS NULL", "IS NOT NULL"} {
+		_ = RegisterOperator(Operator{Name: one, NoValue: true, Builder: buildNullOperator})
+	}
+	for _, one := range []string{"CONTAINS", "STARTS_WITH", "ENDS_WITH"} {
+		_ = RegisterOperator(Operator{Name: one, ListValue: true, Builder: buildMatchOperator})
+	}
+}
+
+// normalizeOperator 操作符转大写，并合并多余的空格
+func normalizeOperator(operator string) string {
+	return strings.Join(strings.Fields(strings.ToUpper(operator)), " ")
+}
+
+// RegisterOperator 注册操作符，已存在的会被覆盖，可用于扩展自定义的操作符
+func RegisterOperator(op Operator) error {
+	op.Name = normalizeOperator(op.Name)
+	if op.Name == "" {
+		return fmt.Errorf("operator name is empty")
+	}
+	if op.Builder == nil {
+		return fmt.Errorf("operator %s builder is nil", op.Name)
+	}
+	operatorRegistry.Lock()
+	defer operatorRegistry.Unlock()
+	operatorRegistry.operators[op.Name] = op
+	return nil
+}
+
+// GetOperator 获取已注册的操作符
+func GetOperator(name string) (Operator, bool) {
+	operatorRegistry.RLock()
+	defer operatorRegistry.RUnlock()
+	op, ok := operatorRegistry.operators[normalizeOperator(name)]
+	return op, ok
+}
+
+// IsSupportedOperator 是否是支持的操作符
+func IsSupportedOperator(name string) bool {
+	_, ok := GetOperator(name)
+	return ok
+}
+
+// OperatorList 所有已注册的操作符
+func OperatorList() []string {
+	operatorRegistry.RLock()
+	defer operatorRegistry.RUnlock()
+	list := make([]string, 0, len(operatorRegistry.operators))
+	for name := range operatorRegistry.operators {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// isListValue 是否为数组类型的值，[]byte 作为单个值处理
+func isListValue(value any) bool {
+	if value == nil {
+		return false
+	}
+	if _, ok := value.([]byte); ok {
+		return false
+	}
+	return reflect.TypeOf(value).Kind() == reflect.Slice
+}
+
+// isRangeValue 是否为 Range 类型的值
+func isRangeValue(value any) bool {
+	switch v := value.(type) {
+	case Range:
+		return true
+	case *Range:
+		return v != nil
+	}
+	return false
+}
+
+func buildCompareOperator(field string, operator string, value any) (string, []any, error) {
+	if isRangeValue(value) {
+		return "", []any{}, fmt.Errorf("operator %s does not accept a range", operator)
+	}
+	if column, ok := value.(Column); ok {
+		quoted, err := QuoteIdentifier(string(column))
+		if err != nil {
+			return "", []any{}, err
+		}
+		return fmt.Sprintf("%s %s %s", field, operator, quoted), []any{}, nil
+	}
+	if query, ok := value.(SubQuery); ok {
+		sqlStr, args, err := query.build()
+		if err != nil {
+			return "", []any{}, err
+		}
+		return fmt.Sprintf("%s %s %s", field, operator, sqlStr), args, nil
+	}
+	return fmt.Sprintf("%s %s ?", field, operator), []any{value}, nil
+}
+
+func buildNullOperator(field string, operator string, _ any) (string, []any, error) {
+	return fmt.Sprintf("%s %s", field, operator), []any{}, nil
+}
+
+func buildInOperator(field string, operator string, value any) (string, []any, error) {
+	if query, ok := value.(SubQuery); ok {
+		sqlStr, args, err := query.build()
+		if err != nil {
+			return "", []any{}, err
+		}
+		return fmt.Sprintf("%s %s %s", field, operator, sqlStr), args, nil
+	}
+	if !isListValue(value) {
+		value = []any{value}
+	}
+	s := reflect.ValueOf(value)
+	//需要去重处理
+	paramList := make([]string, 0)
+	dataList := make([]any, 0)
+	onlyArray := make([]string, 0)
+	for i := 0; i < s.Len(); i++ {
+		ele := s.Index(i).Interface()
+		tempOne := conv.String(ele)
+		if ret, _ := cond.Contains(onlyArray, tempOne); !ret {
+			onlyArray = utils.AppendUniq(onlyArray, tempOne)
+			paramList = append(paramList, "?")
+			dataList = append(dataList, ele)
+		}
+	}
+	if len(dataList) == 0 {
+		return "", []any{}, fmt.Errorf("list is empty")
+	}
+	return fmt.Sprintf("%s %s (%s)", field, operator, strings.Join(paramList, ",")), dataList, nil
+}
+
+// buildBetweenOperator 生成 BETWEEN 语句，Value 可以是 Range 或两个元素的数组
+func buildBetweenOperator(field string, operator string, value any) (string, []any, error) {
+	var start, end any
+	switch v := value.(type) {
+	case Range:
+		start, end = v.Start, v.End
+	case *Range:
+		start, end = v.Start, v.End
+	default:
+		rv := reflect.ValueOf(value)
+		if !rv.IsValid() || (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) || rv.Len() != 2 {
+			return "", []any{}, fmt.Errorf("between value must be Range or two-element slice: %v", value)
+		}
+		start, end = rv.Index(0).Interface(), rv.Index(1).Interface()
+	}
+	if start == nil || end == nil {
+		return "", []any{}, fmt.Errorf("between value can not be nil")
+	}
+	return fmt.Sprintf("%s %s ? AND ?", field, operator), []any{start, end}, nil
+}
+
+// buildMatchOperator 生成 CONTAINS / STARTS_WITH / ENDS_WITH 语句，会对值中的 % 和 _ 进行转义
+// 总是指定 ESCAPE，避免 MySQL 和 PostgreSQL 默认将 \ 作为转义符，数组值生成多个 LIKE 的 OR 条件
+func buildMatchOperator(field string, operator string, value any) (string, []any, error) {
+	if isListValue(value) {
+		rv := reflect.ValueOf(value)
+		if rv.Len() == 0 {
+			return "", []any{}, fmt.Errorf("list is empty")
+		}
+		parts := make([]string, 0, rv.Len())
+		dataList := make([]any, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			sqlStr, args, err := buildMatchOperator(field, operator, rv.Index(i).Interface())
+			if err != nil {
+				return "", []any{}, err
+			}
+			parts = append(parts, sqlStr)
+			dataList = append(dataList, args...)
+		}
+		return strings.Join(parts, " OR "), dataList, nil
+	}
+	if isNilValue(value) {
+		return "", []any{}, fmt.Errorf("operator %s not support nil value", operator)
+	}
+	newValue, escape := escapeMatchValue(conv.String(value))
+	switch operator {
+	case "CONTAINS":
+		newValue = "%" + newValue + "%"
+	case "STARTS_WITH":
+		newValue = newValue + "%"
+	case "ENDS_WITH":
+		newValue = "%" + newValue
+	}
+	return fmt.Sprintf("%s LIKE ? ESCAPE '%s'", field, escape), []any{newValue}, nil
+}
+
+// escapeMatchValue 选择值中不存在的转义符转义 % 和 _，都存在时使用第一个并转义它自身
+func escapeMatchValue(value string) (string, string) {
+	escape, found := lo.Find(likeUseEscapeList, func(one string) bool {
+		return !strings.Contains(value, one)
+	})
+	if !found {
+		escape = likeUseEscapeList[0]
+		value = strings.ReplaceAll(value, escape, escape+escape)
+	}
+	for _, one := range likeUseReplaceList {
+		value = strings.ReplaceAll(value, one, escape+one)
+	}
+	return value, escape
+}
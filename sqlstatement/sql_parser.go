@@ -0,0 +1,479 @@
+package sqlstatement
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ParseError 过滤表达式的语法错误
+type ParseError struct {
+	Pos int    // 出错的位置，从1开始的字符位置
+	Msg string // 错误信息
+}
+
+// Error 错误信息
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Pos, e.Msg)
+}
+
+type filterTokenType int
+
+const (
+	tokenEOF filterTokenType = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenSymbol
+)
+
+// filterToken 过滤表达式的词法单元
+type filterToken struct {
+	typ   filterTokenType
+	text  string
+	value any // 字符串和数字的值
+	pos   int
+}
+
+// isKeyword 是否为指定的关键字，不区分大小写
+func (t filterToken) isKeyword(word string) bool {
+	return t.typ == tokenIdent && strings.EqualFold(t.text, word)
+}
+
+func isFilterIdentRune(r rune) bool {
+	return r == '_' || r == '$' || r == '.' || r == '`' || r == '*' || r >= 0x80 ||
+		(r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// lexFilter 将过滤表达式拆分为词法单元
+func lexFilter(text string) ([]filterToken, error) {
+	runes := []rune(text)
+	tokens := make([]filterToken, 0)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')' || r == ',':
+			tokens = append(tokens, filterToken{typ: tokenSymbol, text: string(r), pos: start + 1})
+			i++
+		case r == '=' || r == '<' || r == '>' || r == '!':
+			for i < len(runes) && strings.ContainsRune("=<>!", runes[i]) {
+				i++
+			}
+			symbol := string(runes[start:i])
+			tokens = append(tokens, filterToken{typ: tokenSymbol, text: symbol, pos: start + 1})
+		case r == '"' || r == '\'':
+			var sb strings.Builder
+			i++
+			closed := false
+			for i < len(runes) {
+				c := runes[i]
+				i++
+				if c == r {
+					closed = true
+					break
+				}
+				if c == '\\' && i < len(runes) {
+					c = runes[i]
+					i++
+					switch c {
+					case 'n':
+						c = '\n'
+					case 't':
+						c = '\t'
+					}
+				}
+				sb.WriteRune(c)
+			}
+			if !closed {
+				return nil, &ParseError{Pos: start + 1, Msg: "unterminated string"}
+			}
+			tokens = append(tokens, filterToken{typ: tokenString, text: string(runes[start:i]), value: sb.String(), pos: start + 1})
+		case unicode.IsDigit(r) || ((r == '-' || r == '+') && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == 'e' || runes[i] == 'E' ||
+				((runes[i] == '-' || runes[i] == '+') && (runes[i-1] == 'e' || runes[i-1] == 'E'))) {
+				i++
+			}
+			numStr := string(runes[start:i])
+			var value any
+			var err error
+			if strings.ContainsAny(numStr, ".eE") {
+				value, err = strconv.ParseFloat(numStr, 64)
+			} else {
+				value, err = strconv.ParseInt(numStr, 10, 64)
+			}
+			if err != nil {
+				return nil, &ParseError{Pos: start + 1, Msg: "invalid number " + numStr}
+			}
+			tokens = append(tokens, filterToken{typ: tokenNumber, text: numStr, value: value, pos: start + 1})
+		case isFilterIdentRune(r):
+			for i < len(runes) && isFilterIdentRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, filterToken{typ: tokenIdent, text: string(runes[start:i]), pos: start + 1})
+		default:
+			return nil, &ParseError{Pos: start + 1, Msg: fmt.Sprintf("unexpected character '%c'", r)}
+		}
+	}
+	return append(tokens, filterToken{typ: tokenEOF, pos: len(runes) + 1}), nil
+}
+
+// filterParser 过滤表达式的语法分析，优先级 NOT > AND > OR
+type filterParser struct {
+	tokens []filterToken
+	index  int
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.index]
+}
+
+func (p *filterParser) next() filterToken {
+	t := p.tokens[p.index]
+	if t.typ != tokenEOF {
+		p.index++
+	}
+	return t
+}
+
+func (p *filterParser) errorf(t filterToken, format string, args ...any) error {
+	msg := fmt.Sprintf(format, args...)
+	if t.typ == tokenEOF {
+		msg += ", got end of input"
+	} else {
+		msg += ", got " + t.text
+	}
+	return &ParseError{Pos: t.pos, Msg: msg}
+}
+
+// parseLogic 解析 AND / OR 连接的条件，多个条件合并为一个 LogicCondition
+func (p *filterParser) parseLogic(operator string, parseChild func() (any, error)) (any, error) {
+	first, err := parseChild()
+	if err != nil {
+		return nil, err
+	}
+	list := []any{first}
+	for p.peek().isKeyword(operator) {
+		p.next()
+		one, err := parseChild()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, one)
+	}
+	if len(list) == 1 {
+		return first, nil
+	}
+	return LogicCondition{Operator: operator, Conditions: list}, nil
+}
+
+func (p *filterParser) parseOr() (any, error) {
+	return p.parseLogic("OR", p.parseAnd)
+}
+
+func (p *filterParser) parseAnd() (any, error) {
+	return p.parseLogic("AND", p.parseUnary)
+}
+
+func (p *filterParser) parseUnary() (any, error) {
+	if p.peek().isKeyword("NOT") {
+		p.next()
+		one, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not{Condition: one}, nil
+	}
+	if p.peek().text == "(" && p.peek().typ == tokenSymbol {
+		p.next()
+		one, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.typ != tokenSymbol || t.text != ")" {
+			return nil, p.errorf(t, "expected )")
+		}
+		return one, nil
+	}
+	return p.parseCondition()
+}
+
+// parseOperator 解析操作符，多个单词的操作符取最长的已注册操作符，如 NOT LIKE BINARY
+func (p *filterParser) parseOperator() (Operator, error) {
+	t := p.peek()
+	if t.typ == tokenSymbol && t.text != "(" && t.text != ")" && t.text != "," {
+		p.next()
+		if op, ok := GetOperator(t.text); ok {
+			return op, nil
+		}
+		return Operator{}, &ParseError{Pos: t.pos, Msg: "operator not support: " + t.text}
+	}
+	words := make([]string, 0)
+	for i := p.index; i < len(p.tokens) && p.tokens[i].typ == tokenIdent && len(words) < 4; i++ {
+		words = append(words, p.tokens[i].text)
+	}
+	for num := len(words); num > 0; num-- {
+		if op, ok := GetOperator(strings.Join(words[:num], " ")); ok {
+			p.index += num
+			return op, nil
+		}
+	}
+	return Operator{}, p.errorf(t, "expected operator")
+}
+
+// parseValue 解析字符串、数字、TRUE、FALSE、NULL
+func (p *filterParser) parseValue() (any, error) {
+	t := p.next()
+	switch {
+	case t.typ == tokenString || t.typ == tokenNumber:
+		return t.value, nil
+	case t.isKeyword("TRUE"):
+		return true, nil
+	case t.isKeyword("FALSE"):
+		return false, nil
+	case t.isKeyword("NULL"):
+		return nil, nil
+	}
+	return nil, p.errorf(t, "expected value")
+}
+
+// parseList 解析 (1, 2, 3) 形式的数组
+func (p *filterParser) parseList() ([]any, error) {
+	p.next()
+	list := make([]any, 0)
+	for {
+		one, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, one)
+		t := p.next()
+		if t.typ == tokenSymbol && t.text == ")" {
+			return list, nil
+		}
+		if t.typ != tokenSymbol || t.text != "," {
+			return nil, p.errorf(t, "expected , or )")
+		}
+	}
+}
+
+func (p *filterParser) parseCondition() (any, error) {
+	t := p.next()
+	if t.typ != tokenIdent {
+		return nil, p.errorf(t, "expected field")
+	}
+	if _, err := SplitIdentifier(t.text); err != nil {
+		return nil, &ParseError{Pos: t.pos, Msg: err.Error()}
+	}
+	op, err := p.parseOperator()
+	if err != nil {
+		return nil, err
+	}
+	con := Condition{Field: t.text, Operator: op.Name}
+	switch {
+	case op.NoValue:
+	case op.Name == "BETWEEN" || op.Name == "NOT BETWEEN":
+		start, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if and := p.next(); !and.isKeyword("AND") {
+			return nil, p.errorf(and, "expected AND")
+		}
+		end, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		con.Value = Range{Start: start, End: end}
+	case p.peek().typ == tokenSymbol && p.peek().text == "(":
+		if con.Value, err = p.parseList(); err != nil {
+			return nil, err
+		}
+	default:
+		if con.Value, err = p.parseValue(); err != nil {
+			return nil, err
+		}
+	}
+	return con, nil
+}
+
+// ParseFilter 将文本过滤表达式解析为 LogicCondition，值全部作为绑定参数
+// 如 status IN (1,2) AND (name STARTS_WITH "ab" OR age >= 18)
+// allowedColumns 不为空时，字段必须在其中
+func ParseFilter(text string, allowedColumns ...string) (LogicCondition, error) {
+	tokens, err := lexFilter(text)
+	if err != nil {
+		return LogicCondition{}, err
+	}
+	p := &filterParser{tokens: tokens}
+	if p.peek().typ == tokenEOF {
+		return LogicCondition{}, nil
+	}
+	node, err := p.parseOr()
+	if err != nil {
+		return LogicCondition{}, err
+	}
+	if t := p.peek(); t.typ != tokenEOF {
+		return LogicCondition{}, p.errorf(t, "expected AND or OR")
+	}
+	group, ok := node.(LogicCondition)
+	if !ok {
+		group = LogicCondition{Operator: defaultLogicOperator, Conditions: []any{node}}
+	}
+	if _, _, err = new(Statement).GenerateWhereClauseStrict(group, allowedColumns...); err != nil {
+		return LogicCondition{}, err
+	}
+	return group, nil
+}
+
+// FormatFilter 将 LogicCondition 转为 ParseFilter 可以解析的文本形式
+func FormatFilter(group LogicCondition) (string, error) {
+	return formatFilterGroup(group, false)
+}
+
+func formatFilterGroup(group LogicCondition, nested bool) (string, error) {
+	operator := strings.ToUpper(group.Operator)
+	if operator == "" {
+		operator = defaultLogicOperator
+	}
+	parts := make([]string, 0, len(group.Conditions))
+	for _, one := range group.Conditions {
+		str, err := formatFilterNode(one)
+		if err != nil {
+			return "", err
+		}
+		if str != "" {
+			parts = append(parts, str)
+		}
+	}
+	str := strings.Join(parts, " "+operator+" ")
+	if nested && len(parts) > 1 {
+		str = "(" + str + ")"
+	}
+	return str, nil
+}
+
+func formatFilterNode(node any) (string, error) {
+	switch c := node.(type) {
+	case Condition:
+		return formatFilterCondition(c)
+	case LogicCondition:
+		return formatFilterGroup(c, true)
+	case Not:
+		str, err := formatFilterNode(c.Condition)
+		if err != nil || str == "" {
+			return str, err
+		}
+		return "NOT " + str, nil
+	}
+	return "", fmt.Errorf("condition type not support in filter: %T", node)
+}
+
+func formatFilterCondition(c Condition) (string, error) {
+	operator := normalizeOperator(c.Operator)
+	if operator == "" {
+		operator = defaultMapOperator
+	}
+	if isNilValue(c.Value) {
+		switch operator {
+		case "=":
+			operator = "IS NULL"
+		case "!=", "<>":
+			operator = "IS NOT NULL"
+		}
+	}
+	if op, ok := GetOperator(operator); ok && op.NoValue {
+		return fmt.Sprintf("%s %s", c.Field, operator), nil
+	}
+
+	if isRangeValue(c.Value) {
+		switch operator {
+		case "=":
+			operator = "BETWEEN"
+		case "!=", "<>":
+			operator = "NOT BETWEEN"
+		}
+	}
+	switch v := c.Value.(type) {
+	case Range:
+		return formatFilterRange(c.Field, operator, v)
+	case *Range:
+		if v != nil {
+			return formatFilterRange(c.Field, operator, *v)
+		}
+	}
+	if isListValue(c.Value) {
+		rv := reflect.ValueOf(c.Value)
+		list := make([]string, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			str, err := formatFilterValue(rv.Index(i).Interface())
+			if err != nil {
+				return "", err
+			}
+			list = append(list, str)
+		}
+		return fmt.Sprintf("%s %s (%s)", c.Field, operator, strings.Join(list, ", ")), nil
+	}
+	str, err := formatFilterValue(c.Value)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s %s %s", c.Field, operator, str), nil
+}
+
+func formatFilterRange(field string, operator string, r Range) (string, error) {
+	start, err := formatFilterValue(r.Start)
+	if err != nil {
+		return "", err
+	}
+	end, err := formatFilterValue(r.End)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s %s %s AND %s", field, operator, start, end), nil
+}
+
+// formatFilterValue 值转为文本，字符串使用双引号
+func formatFilterValue(value any) (string, error) {
+	if isNilValue(value) {
+		return "NULL", nil
+	}
+	switch v := value.(type) {
+	case time.Time:
+		return formatFilterString(v.Format(time.DateTime)), nil
+	case []byte:
+		return formatFilterString(string(v)), nil
+	}
+	rv := reflect.Indirect(reflect.ValueOf(value))
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		str := strconv.FormatFloat(rv.Float(), 'g', -1, 64)
+		if !strings.ContainsAny(str, ".eE") {
+			str += ".0"
+		}
+		return str, nil
+	case reflect.String:
+		return formatFilterString(rv.String()), nil
+	case reflect.Bool:
+		if rv.Bool() {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	}
+	return "", fmt.Errorf("value type not support in filter: %T", value)
+}
+
+func formatFilterString(str string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`)
+	return `"` + replacer.Replace(str) + `"`
+}
@@ -0,0 +1,164 @@
+package sqlstatement
+
+import (
+	"errors"
+	"fmt"
+	"github.com/samber/lo"
+	"strings"
+)
+
+// OrderBy 排序字段
+type OrderBy struct {
+	Field string
+	Desc  bool
+}
+
+// Asc 升序
+func Asc(field string) OrderBy {
+	return OrderBy{Field: field}
+}
+
+// Desc 降序
+func Desc(field string) OrderBy {
+	return OrderBy{Field: field, Desc: true}
+}
+
+// SelectOption 查询语句的可选项
+type SelectOption func(*selectConfig)
+
+type selectConfig struct {
+	orderBy    []OrderBy
+	groupBy    []string
+	having     *LogicCondition
+	tableAlias string
+	joins      []Join
+}
+
+// SetOrderBy 设置排序，可多次调用，按顺序追加
+func SetOrderBy(orders ...OrderBy) SelectOption {
+	return func(c *selectConfig) {
+		c.orderBy = append(c.orderBy, orders...)
+	}
+}
+
+// SetGroupBy 设置分组字段
+func SetGroupBy(fields ...string) SelectOption {
+	return func(c *selectConfig) {
+		c.groupBy = append(c.groupBy, fields...)
+	}
+}
+
+// SetHaving 设置分组后的过滤条件
+func SetHaving(having LogicCondition) SelectOption {
+	return func(c *selectConfig) {
+		c.having = &having
+	}
+}
+
+func newSelectConfig(opts ...SelectOption) *selectConfig {
+	c := new(selectConfig)
+	for _, opt := range opts {
+		if opt != nil {
+			opt(c)
+		}
+	}
+	return c
+}
+
+// selectClause 生成好的 GROUP BY / HAVING / ORDER BY 语句
+type selectClause struct {
+	groupBy    []string
+	having     string
+	havingArgs []any
+	orderBy    []string
+}
+
+// getSelectAliases 获取查询字段中的别名，如 COUNT(*) AS cnt 中的 cnt
+func getSelectAliases(selectStr string) []string {
+	aliases := make([]string, 0)
+	for _, item := range strings.Split(selectStr, ",") {
+		fields := strings.Fields(item)
+		if len(fields) < 3 || !strings.EqualFold(fields[len(fields)-2], "AS") {
+			continue
+		}
+		alias := strings.ReplaceAll(fields[len(fields)-1], identifierQuote, "")
+		if IsValidIdentifier(alias) {
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases
+}
+
+// buildClause 生成 GROUP BY / HAVING / ORDER BY，字段必须在 allColumns 或查询的别名中
+// strict 为false时忽略无效的字段，否则返回错误，HAVING 中的未知字段总是按 st 的 columnPolicy 处理
+func (c *selectConfig) buildClause(st *Statement, allColumns []string, selectStr string, strict bool) (*selectClause, error) {
+	allowed := append(st.buildFieldNames(allColumns), getSelectAliases(selectStr)...)
+	ret := new(selectClause)
+	errs := make([]error, 0)
+
+	quoteField := func(field string) (string, error) {
+		if lo.IndexOf(allowed, st.buildOneFieldName(strings.TrimSpace(field))) < 0 {
+			return "", fmt.Errorf("field not in columns: %s", field)
+		}
+		return QuoteIdentifier(field)
+	}
+
+	for _, field := range c.groupBy {
+		quoted, err := quoteField(field)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		ret.groupBy = append(ret.groupBy, quoted)
+	}
+
+	if c.having != nil {
+		having, _, err := st.filterRelationCondition(*c.having, allowed)
+		if err != nil {
+			return nil, err
+		}
+		if strict {
+			sqlStr, list, err := st.GenerateWhereClauseStrict(having, allowed...)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			ret.having, ret.havingArgs = sqlStr, list
+		} else {
+			ret.having, ret.havingArgs = st.GenerateWhereClause(having)
+		}
+	}
+
+	for _, one := range c.orderBy {
+		quoted, err := quoteField(one.Field)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if one.Desc {
+			quoted += " DESC"
+		} else {
+			quoted += " ASC"
+		}
+		ret.orderBy = append(ret.orderBy, quoted)
+	}
+
+	if strict && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return ret, nil
+}
+
+// String 拼接到 WHERE 之后的语句
+func (c *selectClause) String() string {
+	parts := make([]string, 0)
+	if len(c.groupBy) > 0 {
+		parts = append(parts, "GROUP BY "+strings.Join(c.groupBy, ", "))
+	}
+	if c.having != "" {
+		parts = append(parts, "HAVING "+c.having)
+	}
+	if len(c.orderBy) > 0 {
+		parts = append(parts, "ORDER BY "+strings.Join(c.orderBy, ", "))
+	}
+	return strings.Join(parts, " ")
+}
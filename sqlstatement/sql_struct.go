@@ -3,7 +3,8 @@ package sqlstatement
 import (
 	"fmt"
 	"github.com/Masterminds/squirrel"
-	"github.com/samber/lo"
+	"reflect"
+	"time"
 )
 
 type SqlStruct struct {
@@ -11,6 +12,19 @@ type SqlStruct struct {
 	tableName                 string //表名
 	convertTableAndColumnType string
 	columnTagName             string
+	strictMode                bool            //严格模式，无效的条件返回错误而不是忽略
+	columnPolicy              ColumnPolicy    //条件中出现未知字段时的处理方式
+	allowFullTable            bool            //是否允许没有where条件的更新和删除
+	dialect                   Dialect         //数据库方言，默认为 MySQL
+	meta                      *structMeta     //缓存的表名和字段，由 TypedSqlStruct 设置
+	zeroValuePolicy           ZeroValuePolicy //插入和更新时零值字段的处理方式
+}
+
+// structMeta 结构体对应的表名和所有字段
+type structMeta struct {
+	tableName string
+	columns   []string
+	fields    *structFields
 }
 
 type Option func(*SqlStruct)
@@ -47,21 +61,251 @@ func SetStructData(d any) Option {
 	}
 }
 
-func (s *SqlStruct) commGetTableNameAndColumns(in any) (string, map[string]any, error) {
-	if in == nil {
-		return "", nil, fmt.Errorf("please use SetStructData func")
+// SetStrictMode 设置严格模式，无效的查询条件会返回错误，而不是被忽略
+func SetStrictMode(strict bool) Option {
+	return func(s *SqlStruct) {
+		s.strictMode = strict
+	}
+}
+
+// SetColumnPolicy 设置条件中出现未知字段时的处理方式，严格模式下总是返回错误
+func SetColumnPolicy(policy ColumnPolicy) Option {
+	return func(s *SqlStruct) {
+		s.columnPolicy = policy
+	}
+}
+
+// SetAllowFullTable 设置是否允许没有where条件的更新和删除，默认不允许，返回 ErrFullTableWrite
+func SetAllowFullTable(allow bool) Option {
+	return func(s *SqlStruct) {
+		s.allowFullTable = allow
 	}
+}
+
+// SetDialect 设置数据库方言，默认为 MySQL
+func SetDialect(d Dialect) Option {
+	return func(s *SqlStruct) {
+		s.dialect = d
+	}
+}
+
+// SetZeroValuePolicy 设置插入和更新时零值字段的处理方式，默认只忽略nil
+func SetZeroValuePolicy(policy ZeroValuePolicy) Option {
+	return func(s *SqlStruct) {
+		s.zeroValuePolicy = policy
+	}
+}
+
+// statement 使用相同配置的 Statement
+func (s *SqlStruct) statement() *Statement {
+	policy := s.columnPolicy
+	if s.strictMode {
+		policy = ColumnPolicyReject
+	}
+	return NewStatement(SetStatementColumnPolicy(policy), SetStatementAllowFullTable(s.allowFullTable),
+		SetStatementDialect(s.dialect))
+}
 
+// rebind 将生成的语句转为方言格式
+func (s *SqlStruct) rebind(sqlStr string, args []any, err error) (string, []any, error) {
+	if err != nil {
+		return "", nil, err
+	}
+	sqlStr, args = s.statement().rebind(sqlStr, args)
+	return sqlStr, args, nil
+}
+
+func (s *SqlStruct) getTagNames() []string {
 	tagNames := make([]string, 0)
 	if s.columnTagName != "" {
 		tagNames = append(tagNames, s.columnTagName)
 	}
-	tableName, columnsMap, err := StructToColumnsAndValues(in, s.convertTableAndColumnType, tagNames...)
+	return tagNames
+}
+
+// commGetAllColumns 获取结构体对应的所有字段名，包括值为nil的字段
+func (s *SqlStruct) commGetAllColumns(in any) ([]string, error) {
+	if in == nil {
+		return nil, fmt.Errorf("please use SetStructData func")
+	}
+	if s.meta != nil {
+		return append([]string{}, s.meta.columns...), nil
+	}
+	return structColumnNames(in, s.convertTableAndColumnType, s.getTagNames()...)
+}
+
+// generateWhereClause 生成where语句，字段必须为结构体的字段或 表名.字段
+// forWrite 为true时用于更新和删除，条件为空且不允许全表操作时返回 ErrFullTableWrite
+func (s *SqlStruct) generateWhereClause(in any, tableName string, whereCondition LogicCondition, forWrite bool) (string, []any, error) {
+	columns, err := s.commGetAllColumns(in)
+	if err != nil {
+		return "", nil, err
+	}
+	return s.generateWhereClauseByColumns(s.statement().tableColumns(tableName, columns), whereCondition, forWrite)
+}
+
+// generateWhereClauseByColumns 生成where语句，字段必须在 columns 中，严格模式下检查所有条件
+func (s *SqlStruct) generateWhereClauseByColumns(columns []string, whereCondition LogicCondition, forWrite bool) (string, []any, error) {
+	st := s.statement()
+	if !s.strictMode {
+		if forWrite {
+			return st.fullTableWhere(whereCondition, columns)
+		}
+		return st.whereByColumns(whereCondition, columns, false)
+	}
+	sqlStr, list, err := st.GenerateWhereClauseStrict(whereCondition, columns...)
+	if err == nil && forWrite && sqlStr == "" && !s.allowFullTable {
+		err = ErrFullTableWrite
+	}
+	return sqlStr, list, err
+}
+
+// checkWhereMap 严格模式下检查Map条件
+func (s *SqlStruct) checkWhereMap(in any, tableName string, whereMap map[string]any) error {
+	if !s.strictMode {
+		return nil
+	}
+	_, _, err := s.generateWhereClause(in, tableName, s.statement().logicConditionByMap(whereMap), false)
+	return err
+}
+
+// commGetStructFields 获取结构体的值和所有列的属性
+func (s *SqlStruct) commGetStructFields(in any) (*structFields, reflect.Value, error) {
+	v, err := structValue(in)
+	if err != nil {
+		return nil, reflect.Value{}, err
+	}
+	if s.meta != nil {
+		return s.meta.fields, v, nil
+	}
+	return parseStructFields(v.Type(), s.convertTableAndColumnType, s.getTagNames()...), v, nil
+}
+
+// insertColumnMap 按标签处理插入的字段：不插入只读字段和值为空的自增字段
+// created、updated 值为空时设置为当前时间，version 值为空时设置为1
+func (s *SqlStruct) insertColumnMap(in any, columnMap map[string]any) (map[string]any, error) {
+	info, v, err := s.commGetStructFields(in)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, field := range info.fields {
+		value := v.Field(field.index)
+		switch {
+		case field.readonly:
+			delete(columnMap, field.column)
+		case field.autoIncr && value.IsZero():
+			delete(columnMap, field.column)
+		case (field.created || field.updated) && value.IsZero():
+			if t, ok := autoTimeValue(value.Type(), now); ok {
+				columnMap[field.column] = t
+			}
+		case field.version && value.IsZero():
+			columnMap[field.column] = 1
+		}
+	}
+	return columnMap, nil
+}
+
+// updateColumnMap 按标签处理更新的字段：不更新主键、自增、只读和 created 字段，updated 设置为当前时间
+// version 不为空时更新为原值加1，并返回检查原值的条件；主键不为空时返回主键的条件
+func (s *SqlStruct) updateColumnMap(in any, updateMap map[string]any) (map[string]any, []any, []any, error) {
+	info, v, err := s.commGetStructFields(in)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	now := time.Now()
+	pkConditions := make([]any, 0)
+	versionConditions := make([]any, 0)
+	for _, field := range info.fields {
+		value := v.Field(field.index)
+		if field.pk && !value.IsZero() {
+			pkConditions = append(pkConditions, Condition{Field: field.column, Operator: "=", Value: value.Interface()})
+		}
+		switch {
+		case field.pk || field.autoIncr || field.readonly || field.created:
+			delete(updateMap, field.column)
+		case field.updated:
+			if t, ok := autoTimeValue(value.Type(), now); ok {
+				updateMap[field.column] = t
+			}
+		case field.version:
+			delete(updateMap, field.column)
+			if next, ok := nextVersion(value); ok {
+				updateMap[field.column] = next
+				versionConditions = append(versionConditions, Condition{Field: field.column, Operator: "=", Value: value.Interface()})
+			}
+		}
+	}
+	return updateMap, pkConditions, versionConditions, nil
+}
+
+// nextVersion version 字段加1，值为空或不是整数时不处理
+func nextVersion(value reflect.Value) (any, bool) {
+	value = reflect.Indirect(value)
+	if !value.IsValid() || value.IsZero() {
+		return nil, false
+	}
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int() + 1, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return value.Uint() + 1, true
+	}
+	return nil, false
+}
+
+// structType 去掉指针后的结构体类型
+func structType(in any) reflect.Type {
+	t := reflect.TypeOf(in)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// commGetTableName 获取 structData 对应的表名
+func (s *SqlStruct) commGetTableName() (string, error) {
+	if s.meta != nil {
+		return s.meta.tableName, nil
+	}
+	tableName, _, err := s.commGetTableNameAndColumns(s.structData)
+	return tableName, err
+}
+
+// commGetColumnMap 按零值策略获取结构体的字段和值，columns 不为空时只获取这些字段，且不忽略零值
+func (s *SqlStruct) commGetColumnMap(in any, columns ...string) (map[string]any, error) {
+	info, v, err := s.commGetStructFields(in)
+	if err != nil {
+		return nil, err
+	}
+	return structColumnMap(v, info, s.zeroValuePolicy, columns...), nil
+}
+
+// commGetTableNameAndColumns 获取表名和结构体中按零值策略保留的字段，结构体必须与 structData 的类型一致
+func (s *SqlStruct) commGetTableNameAndColumns(in any) (string, map[string]any, error) {
+	if in == nil {
+		return "", nil, fmt.Errorf("please use SetStructData func")
+	}
+	if s.structData != nil && structType(in) != structType(s.structData) {
+		return "", nil, fmt.Errorf("struct type not match: %s, %T", structType(s.structData), in)
+	}
+
+	info, v, err := s.commGetStructFields(in)
 	if err != nil {
 		return "", nil, err
 	}
-	if s.tableName != "" {
-		tableName = s.tableName
+	columnsMap := structColumnMap(v, info, s.zeroValuePolicy)
+	tableName := info.getTableName(v, s.convertTableAndColumnType)
+	if s.meta != nil {
+		tableName = s.meta.tableName
+	} else {
+		if s.tableName != "" {
+			tableName = s.tableName
+		}
+		if tableName, err = addCodeForOneColumn(tableName); err != nil {
+			return "", nil, err
+		}
 	}
 
 	//设置默认值
@@ -72,154 +316,335 @@ func (s *SqlStruct) commGetTableNameAndColumns(in any) (string, map[string]any,
 	return tableName, columnsMap, nil
 }
 
+// insertValues 插入的表名和字段
+func (s *SqlStruct) insertValues(in any) (string, map[string]any, error) {
+	tableName, columnMap, err := s.commGetTableNameAndColumns(in)
+	if err != nil {
+		return "", nil, err
+	}
+	if columnMap, err = s.insertColumnMap(in, columnMap); err != nil {
+		return "", nil, err
+	}
+	return tableName, columnMap, nil
+}
+
+// InsertColumns InsertSql 实际插入的字段，用于检查零值策略和标签的效果
+func (s *SqlStruct) InsertColumns(in any) ([]string, error) {
+	_, columnMap, err := s.insertValues(in)
+	if err != nil {
+		return nil, err
+	}
+	columns, _ := getSliceByMap(columnMap)
+	return columns, nil
+}
+
 // InsertSql 插入的sql语句
 func (s *SqlStruct) InsertSql(in any) (string, []any, error) {
-	tableName, columnMap, err := s.commGetTableNameAndColumns(in)
+	tableName, columnMap, err := s.insertValues(in)
 	if err != nil {
 		return "", nil, err
 	}
 	columns, values := getSliceByMap(columnMap)
-	columns = addCodeForColumns(columns)
-	return squirrel.Insert(tableName).Columns(columns...).Values(values...).ToSql()
+	if columns, err = addCodeForColumns(columns); err != nil {
+		return "", nil, err
+	}
+	return s.rebind(squirrel.Insert(tableName).Columns(columns...).Values(values...).ToSql())
 }
 
 // InsertSqlByMap 插入的sql语句
 func (s *SqlStruct) InsertSqlByMap(inMap map[string]any) (string, []any, error) {
-	tableName, columnMap, err := s.commGetTableNameAndColumns(s.structData)
+	tableName, err := s.commGetTableName()
 	if err != nil {
 		return "", nil, err
 	}
-	columns, _ := getSliceByMap(columnMap)
-	st := new(Statement)
+	columns, err := s.commGetAllColumns(s.structData)
+	if err != nil {
+		return "", nil, err
+	}
+	st := s.statement()
 	sqlStr, values := st.InsertSql(tableName, columns, inMap)
 	return sqlStr, values, nil
 }
 
+// BatchInsertSql 批量插入的sql语句，rows 为结构体的数组，超过限制时会拆分为多条语句
+func (s *SqlStruct) BatchInsertSql(rows any, opts ...BatchOption) ([]BatchSql, error) {
+	tableName, columns, rowMaps, err := s.commGetBatchRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	return s.statement().batchInsertSql(tableName, columns, rowMaps, nil, opts...)
+}
+
+// UpsertSql 插入或更新的sql语句，冲突处理方式见 Upsert
+func (s *SqlStruct) UpsertSql(in any, upsert Upsert) (string, []any, error) {
+	list, err := s.BatchUpsertSql([]any{in}, upsert)
+	if err != nil {
+		return "", nil, err
+	}
+	return list[0].Sql, list[0].Args, nil
+}
+
+// BatchUpsertSql 批量插入或更新的sql语句，rows 为结构体的数组，超过限制时会拆分为多条语句
+func (s *SqlStruct) BatchUpsertSql(rows any, upsert Upsert, opts ...BatchOption) ([]BatchSql, error) {
+	tableName, columns, rowMaps, err := s.commGetBatchRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	return s.statement().batchInsertSql(tableName, columns, rowMaps, &upsert, opts...)
+}
+
+// commGetBatchRows 将结构体数组转为表名、所有字段和每行的数据
+func (s *SqlStruct) commGetBatchRows(rows any) (string, []string, []map[string]any, error) {
+	rv := reflect.ValueOf(rows)
+	if !rv.IsValid() || (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) {
+		return "", nil, nil, fmt.Errorf("rows must be slice: %T", rows)
+	}
+	if rv.Len() == 0 {
+		return "", nil, nil, fmt.Errorf("rows is empty")
+	}
+	var tableName string
+	var rowType reflect.Type
+	rowMaps := make([]map[string]any, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		one := rv.Index(i).Interface()
+		if rowType == nil {
+			rowType = reflect.TypeOf(one)
+		} else if reflect.TypeOf(one) != rowType {
+			return "", nil, nil, fmt.Errorf("rows type not same: %s, %T", rowType, one)
+		}
+		oneTableName, columnMap, err := s.insertValues(one)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		tableName = oneTableName
+		rowMaps = append(rowMaps, columnMap)
+	}
+	columns, err := s.commGetAllColumns(rv.Index(0).Interface())
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return tableName, columns, rowMaps, nil
+}
+
 // DeleteSql 删除的sql语句
 func (s *SqlStruct) DeleteSql(whereCondition LogicCondition) (string, []any, error) {
-	tableName, _, err := s.commGetTableNameAndColumns(s.structData)
+	tableName, err := s.commGetTableName()
+	if err != nil {
+		return "", nil, err
+	}
+	sqlStr, list, err := s.generateWhereClause(s.structData, tableName, whereCondition, true)
 	if err != nil {
 		return "", nil, err
 	}
-	sqlStr, list := new(Statement).GenerateWhereClause(whereCondition)
 	sqlState := squirrel.Delete(tableName)
 	if sqlStr == "" {
-		return sqlState.ToSql()
+		return s.rebind(sqlState.ToSql())
 	}
-	return sqlState.Where(sqlStr, list...).ToSql()
+	return s.rebind(sqlState.Where(sqlStr, list...).ToSql())
 }
 
 // DeleteSqlByMap 删除的sql语句，map里的关系是And关系
 func (s *SqlStruct) DeleteSqlByMap(whereMap map[string]any) (string, []any, error) {
-	tableName, columnMap, err := s.commGetTableNameAndColumns(s.structData)
+	tableName, err := s.commGetTableName()
 	if err != nil {
 		return "", nil, err
 	}
-	columns, _ := getSliceByMap(columnMap)
-	st := new(Statement)
-	sqlStr, values := st.DeleteSql(tableName, columns, whereMap)
-	return sqlStr, values, nil
+	columns, err := s.commGetAllColumns(s.structData)
+	if err != nil {
+		return "", nil, err
+	}
+	if err = s.checkWhereMap(s.structData, tableName, whereMap); err != nil {
+		return "", nil, err
+	}
+	st := s.statement()
+	return s.rebind(st.deleteSql(tableName, columns, st.logicConditionByMap(whereMap)))
 }
 
-// UpdateSql 修改的sql语句
-func (s *SqlStruct) UpdateSql(in any, columns []string, whereCondition LogicCondition) (string, []any, error) {
+// updateValues 更新的表名、字段，以及主键和 version 的条件，columns 为空时按零值策略更新所有字段
+func (s *SqlStruct) updateValues(in any, columns []string) (string, map[string]any, []any, []any, error) {
 	tableName, allColumnMap, err := s.commGetTableNameAndColumns(in)
 	if err != nil {
-		return "", nil, err
+		return "", nil, nil, nil, err
+	}
+	if len(columns) > 0 {
+		if allColumnMap, err = s.commGetColumnMap(in, new(Statement).buildFieldNames(columns)...); err != nil {
+			return "", nil, nil, nil, err
+		}
 	}
+	updateMap, pkConditions, versionConditions, err := s.updateColumnMap(in, allColumnMap)
+	if err != nil {
+		return "", nil, nil, nil, err
+	}
+	return tableName, updateMap, pkConditions, versionConditions, nil
+}
 
-	st := new(Statement)
-	columns = st.buildFieldNames(columns)
+// UpdateColumns UpdateSql 实际更新的字段，用于检查零值策略和标签的效果
+func (s *SqlStruct) UpdateColumns(in any, columns []string) ([]string, error) {
+	_, updateMap, _, _, err := s.updateValues(in, columns)
+	if err != nil {
+		return nil, err
+	}
+	updateColumns, _ := getSliceByMap(updateMap)
+	return updateColumns, nil
+}
 
-	updateMap := make(map[string]any)
-	if len(columns) == 0 {
-		updateMap = allColumnMap
-	} else {
-		lo.ForEach(columns, func(item string, i int) {
-			if val, ok := allColumnMap[item]; ok {
-				updateMap[item] = val
-			}
-		})
+// UpdateSql 修改的sql语句，不修改主键、自增、只读和 created 字段，条件为空时使用主键作为条件
+// columns 为空时按零值策略更新所有字段，不为空时只更新这些字段，且零值也会更新
+func (s *SqlStruct) UpdateSql(in any, columns []string, whereCondition LogicCondition) (string, []any, error) {
+	tableName, updateMap, pkConditions, versionConditions, err := s.updateValues(in, columns)
+	if err != nil {
+		return "", nil, err
 	}
 	newUpdateMap := make(map[string]any)
 	for k, v := range updateMap {
-		newUpdateMap[addCodeForOneColumn(k)] = v
+		column, err := addCodeForOneColumn(k)
+		if err != nil {
+			return "", nil, err
+		}
+		value, args, err := assignmentValue(s.statement().getDialect(), column, v)
+		if err != nil {
+			return "", nil, err
+		}
+		switch v.(type) {
+		case Expr, UpdateExpr:
+			newUpdateMap[column] = squirrel.Expr(value, args...)
+		default:
+			newUpdateMap[column] = v
+		}
 	}
 
-	sqlStr, list := new(Statement).GenerateWhereClause(whereCondition)
+	if len(whereCondition.Conditions) == 0 {
+		whereCondition = appendConditions(whereCondition, pkConditions...)
+	}
+	whereCondition = appendConditions(whereCondition, versionConditions...)
+	sqlStr, list, err := s.generateWhereClause(in, tableName, whereCondition, true)
+	if err != nil {
+		return "", nil, err
+	}
 	sqlState := squirrel.Update(tableName).SetMap(newUpdateMap)
 	if sqlStr == "" {
-		return sqlState.ToSql()
+		return s.rebind(sqlState.ToSql())
 	}
-	return sqlState.Where(sqlStr, list...).ToSql()
+	return s.rebind(sqlState.Where(sqlStr, list...).ToSql())
 }
 
-// UpdateSqlByMap 修改的sql语句
+// UpdateSqlByMap 修改的sql语句，字段的处理与 UpdateSql 一致
 func (s *SqlStruct) UpdateSqlByMap(in any, columns []string, whereMap map[string]any) (string, []any, error) {
-	tableName, allColumnMap, err := s.commGetTableNameAndColumns(in)
+	tableName, updateMap, pkConditions, versionConditions, err := s.updateValues(in, columns)
 	if err != nil {
 		return "", nil, err
 	}
 
-	updateMap := make(map[string]any)
-	if len(columns) == 0 {
-		updateMap = allColumnMap
-	} else {
-		lo.ForEach(columns, func(item string, i int) {
-			if val, ok := allColumnMap[item]; ok {
-				updateMap[item] = val
-			}
-		})
+	allColumns, err := s.commGetAllColumns(in)
+	if err != nil {
+		return "", nil, err
 	}
-
-	st := new(Statement)
-	allColumns, _ := getSliceByMap(allColumnMap)
-	sqlStr, values := st.UpdateSql(tableName, allColumns, updateMap, whereMap)
-	return sqlStr, values, nil
+	if err = s.checkWhereMap(in, tableName, whereMap); err != nil {
+		return "", nil, err
+	}
+	st := s.statement()
+	whereCondition := st.logicConditionByMap(whereMap)
+	if len(whereCondition.Conditions) == 0 {
+		whereCondition = appendConditions(whereCondition, pkConditions...)
+	}
+	whereCondition = appendConditions(whereCondition, versionConditions...)
+	return s.rebind(st.updateSql(tableName, allColumns, updateMap, whereCondition))
 }
 
 // UpdateSqlWithUpdateMap 更新的sql语句，map里的关系是And关系
 func (s *SqlStruct) UpdateSqlWithUpdateMap(updateMap map[string]any, whereMap map[string]any) (string, []any, error) {
-	tableName, columnMap, err := s.commGetTableNameAndColumns(s.structData)
+	tableName, err := s.commGetTableName()
 	if err != nil {
 		return "", nil, err
 	}
-	allColumns, _ := getSliceByMap(columnMap)
-	st := new(Statement)
-	sqlStr, values := st.UpdateSql(tableName, allColumns, updateMap, whereMap)
-	return sqlStr, values, nil
+	allColumns, err := s.commGetAllColumns(s.structData)
+	if err != nil {
+		return "", nil, err
+	}
+	if err = s.checkWhereMap(s.structData, tableName, whereMap); err != nil {
+		return "", nil, err
+	}
+	st := s.statement()
+	return s.rebind(st.updateSql(tableName, allColumns, updateMap, st.logicConditionByMap(whereMap)))
 }
 
 // SelectSql 查询的sql语句
-func (s *SqlStruct) SelectSql(selectStr string, whereCondition LogicCondition, offset, limit int) (string, []any, error) {
-	tableName, _, err := s.commGetTableNameAndColumns(s.structData)
+func (s *SqlStruct) SelectSql(selectStr string, whereCondition LogicCondition, offset, limit int, opts ...SelectOption) (string, []any, error) {
+	tableName, err := s.commGetTableName()
 	if err != nil {
 		return "", nil, err
 	}
-
-	if selectStr == "" {
-		selectStr = "*"
+	allColumns, err := s.commGetAllColumns(s.structData)
+	if err != nil {
+		return "", nil, err
+	}
+	config := newSelectConfig(opts...)
+	allowed := config.allowedColumns(tableName, allColumns)
+	from, err := quoteTableWithAlias(tableName, config.tableAlias)
+	if err != nil {
+		return "", nil, err
+	}
+	joins, err := config.buildJoins(s.statement(), allowed, s.strictMode)
+	if err != nil {
+		return "", nil, err
+	}
+	clause, err := config.buildClause(s.statement(), allowed, selectStr, s.strictMode)
+	if err != nil {
+		return "", nil, err
+	}
+	selectStr, err = new(Statement).buildSelectColumns(allowed, selectStr)
+	if err != nil {
+		return "", nil, err
 	}
 
-	sqlStr, list := new(Statement).GenerateWhereClause(whereCondition)
-	sqlState := squirrel.Select(selectStr).From(tableName)
+	sqlStr, list, err := s.generateWhereClauseByColumns(allowed, whereCondition, false)
+	if err != nil {
+		return "", nil, err
+	}
+	sqlState := squirrel.Select(selectStr).From(from)
+	for _, one := range joins {
+		sqlState = sqlState.JoinClause(one.sql, one.args...)
+	}
 	if sqlStr != "" {
 		sqlState = sqlState.Where(sqlStr, list...)
 	}
+	if len(clause.groupBy) > 0 {
+		sqlState = sqlState.GroupBy(clause.groupBy...)
+	}
+	if clause.having != "" {
+		sqlState = sqlState.Having(clause.having, clause.havingArgs...)
+	}
+	if len(clause.orderBy) > 0 {
+		sqlState = sqlState.OrderBy(clause.orderBy...)
+	}
 	if offset >= 0 && limit > 0 {
 		sqlState = sqlState.Offset(uint64(offset)).Limit(uint64(limit))
 	}
-	return sqlState.ToSql()
+	return s.rebind(sqlState.ToSql())
 }
 
 // SelectSqlByMap 查询的sql语句
-func (s *SqlStruct) SelectSqlByMap(selectStr string, whereMap map[string]any, offset, limit int) (string, []any, error) {
-	tableName, columnMap, err := s.commGetTableNameAndColumns(s.structData)
+func (s *SqlStruct) SelectSqlByMap(selectStr string, whereMap map[string]any, offset, limit int, opts ...SelectOption) (string, []any, error) {
+	tableName, err := s.commGetTableName()
 	if err != nil {
 		return "", nil, err
 	}
-	columns, _ := getSliceByMap(columnMap)
-	st := new(Statement)
-	sqlStr, values := st.SelectSql(tableName, columns, selectStr, whereMap, offset, limit)
-	return sqlStr, values, nil
+	columns, err := s.commGetAllColumns(s.structData)
+	if err != nil {
+		return "", nil, err
+	}
+	if s.strictMode {
+		config := newSelectConfig(opts...)
+		allowed := config.allowedColumns(tableName, columns)
+		if _, _, err = s.generateWhereClauseByColumns(allowed, s.statement().logicConditionByMap(whereMap), false); err != nil {
+			return "", nil, err
+		}
+		if _, err = config.buildJoins(s.statement(), allowed, true); err != nil {
+			return "", nil, err
+		}
+		if _, err = config.buildClause(s.statement(), allowed, selectStr, true); err != nil {
+			return "", nil, err
+		}
+	}
+	return s.statement().SelectSqlE(tableName, columns, selectStr, whereMap, offset, limit, opts...)
 }
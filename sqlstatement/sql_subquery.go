@@ -0,0 +1,49 @@
+package sqlstatement
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SubQuery 子查询，可作为 Condition 的值，如 user_id IN (SELECT id FROM user WHERE ...)
+type SubQuery struct {
+	Sql  string
+	Args []any
+}
+
+// Exists EXISTS / NOT EXISTS 条件，可放在 LogicCondition.Conditions 中
+type Exists struct {
+	Query SubQuery
+	Not   bool
+}
+
+// NewSubQuery 通过生成好的sql创建子查询，可直接使用builder的返回值，如 NewSubQuery(st.SelectSql(...))
+// 方言的 $n、:pN 占位符会转回 ?，嵌套后由外层语句统一编号
+func NewSubQuery(sqlStr string, args []any) SubQuery {
+	return SubQuery{Sql: sqlStr, Args: args}
+}
+
+// build 生成括号包裹的子查询
+func (q SubQuery) build() (string, []any, error) {
+	sqlStr := strings.TrimSpace(q.Sql)
+	if sqlStr == "" {
+		return "", []any{}, fmt.Errorf("sub query is empty")
+	}
+	sqlStr, args, err := unbindSql(sqlStr, q.Args)
+	if err != nil {
+		return "", []any{}, err
+	}
+	return fmt.Sprintf("(%s)", sqlStr), append(make([]any, 0, len(args)), args...), nil
+}
+
+// build 生成 EXISTS 语句
+func (e Exists) build() (string, []any, error) {
+	sqlStr, args, err := e.Query.build()
+	if err != nil {
+		return "", []any{}, err
+	}
+	if e.Not {
+		return "NOT EXISTS " + sqlStr, args, nil
+	}
+	return "EXISTS " + sqlStr, args, nil
+}
@@ -0,0 +1,263 @@
+package sqlstatement
+
+import (
+	"github.com/samber/lo"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// xormTagName xorm 的标签名，字段的 pk、autoincr 等属性总是从这个标签读取
+const xormTagName = "xorm"
+
+// structField 结构体字段对应的列及其属性，兼容 xorm 的标签
+type structField struct {
+	index    int
+	column   string
+	pk       bool // 主键，更新时不修改，条件为空时作为更新的条件
+	autoIncr bool // 自增，值为空时插入不指定
+	readonly bool // <- 只从数据库读取，不插入也不更新
+	created  bool // 插入时值为空则设置为当前时间，更新时不修改
+	updated  bool // 插入时值为空则设置为当前时间，更新时总是设置为当前时间
+	version  bool // 乐观锁，插入时为1，更新时加1并检查原值
+	omitZero bool // omitempty 值为零值时不插入也不更新
+}
+
+// ZeroValuePolicy 结构体转为插入或更新的字段时，零值字段的处理方式
+type ZeroValuePolicy int
+
+const (
+	ZeroValueOmitNil    ZeroValuePolicy = iota // 只忽略值为nil的字段，默认
+	ZeroValueOmitZero                          // 忽略所有零值字段，如 ""、0、nil
+	ZeroValueIncludeAll                        // 包括所有字段，nil 为 NULL
+)
+
+// structFields 结构体的所有列
+type structFields struct {
+	structName string
+	tableName  string // TableName() 方法返回的表名
+	fields     []structField
+}
+
+// tableNamer 实现 TableName() 的结构体使用返回值作为表名
+type tableNamer interface {
+	TableName() string
+}
+
+// xormKeywords xorm 标签中不是列名的关键字
+var xormKeywords = map[string]bool{
+	"pk": true, "autoincr": true, "null": true, "notnull": true, "not": true, "unique": true, "index": true,
+	"extends": true, "deleted": true, "created": true, "updated": true, "version": true, "cascade": true,
+	"nocache": true, "utc": true, "local": true, "unsigned": true, "-": true, "<-": true, "->": true,
+}
+
+// xormTypes xorm 标签中的字段类型，不是列名
+var xormTypes = map[string]bool{
+	"bit": true, "tinyint": true, "smallint": true, "mediumint": true, "int": true, "integer": true, "bigint": true,
+	"bool": true, "boolean": true, "float": true, "double": true, "real": true, "decimal": true, "numeric": true,
+	"char": true, "varchar": true, "nchar": true, "nvarchar": true, "tinytext": true, "text": true,
+	"mediumtext": true, "longtext": true, "binary": true, "varbinary": true, "tinyblob": true, "blob": true,
+	"mediumblob": true, "longblob": true, "bytea": true, "date": true, "datetime": true, "time": true,
+	"timestamp": true, "timestampz": true, "year": true, "json": true, "jsonb": true, "enum": true, "set": true,
+	"uuid": true, "serial": true, "bigserial": true,
+}
+
+// splitTagTokens 按空格拆分 xorm 标签，单引号中的空格不拆分
+func splitTagTokens(tag string) []string {
+	tokens := make([]string, 0)
+	var sb strings.Builder
+	inQuote := false
+	for _, r := range tag {
+		switch {
+		case r == '\'':
+			inQuote = !inQuote
+			sb.WriteRune(r)
+		case unicode.IsSpace(r) && !inQuote:
+			if sb.Len() > 0 {
+				tokens = append(tokens, sb.String())
+				sb.Reset()
+			}
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	if sb.Len() > 0 {
+		tokens = append(tokens, sb.String())
+	}
+	return tokens
+}
+
+// parseXormTag 解析 xorm 标签，返回列名和属性，skip 为true表示不是数据库字段
+// 关键字和类型可以带括号参数，如 index(idx_uid)、comment('id')、varchar(64)，列名只能是合法的标识符或单引号包裹的名称
+func parseXormTag(tag string) (column string, field structField, skip bool) {
+	tokens := splitTagTokens(tag)
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		name := strings.ToLower(token)
+		hasArgs := false
+		if index := strings.Index(name, "("); index >= 0 {
+			name, hasArgs = name[:index], true
+		}
+		switch name {
+		case "-":
+			return "", field, true
+		case "pk":
+			field.pk = true
+		case "autoincr":
+			field.autoIncr = true
+		case "<-":
+			field.readonly = true
+		case "created":
+			field.created = true
+		case "updated":
+			field.updated = true
+		case "version":
+			field.version = true
+		case "omitempty":
+			field.omitZero = true
+		case "default", "comment":
+			if !hasArgs {
+				i++ //后面一个为默认值或注释
+			}
+		}
+		if xormKeywords[name] || xormTypes[name] || name == "default" || name == "comment" || name == "omitempty" || hasArgs {
+			continue
+		}
+		if strings.HasPrefix(token, "'") && strings.HasSuffix(token, "'") && len(token) >= 2 {
+			column = token[1 : len(token)-1]
+			continue
+		}
+		if column == "" && IsValidIdentifier(token) {
+			column = token
+		}
+	}
+	return column, field, false
+}
+
+// tagColumnName 从其他标签获取列名和 omitempty，如 json:"name,omitempty"，found 为false表示没有设置列名
+func tagColumnName(fi reflect.StructField, tagName string) (column string, omitZero bool, found bool) {
+	parts := strings.Split(fi.Tag.Get(tagName), ",")
+	column = strings.TrimSpace(parts[0])
+	for _, one := range parts[1:] {
+		if strings.TrimSpace(one) == "omitempty" {
+			omitZero = true
+		}
+	}
+	return column, omitZero, column != ""
+}
+
+// parseStructFields 解析结构体的所有列，列名按 tagNames 的顺序查找，找不到时按 convertType 转换字段名
+// 标签为 - 的字段不是数据库字段
+func parseStructFields(t reflect.Type, convertType string, tagNames ...string) *structFields {
+	info := &structFields{structName: t.Name(), fields: make([]structField, 0, t.NumField())}
+	if namer, ok := reflect.New(t).Interface().(tableNamer); ok {
+		info.tableName = namer.TableName()
+	}
+	for i := 0; i < t.NumField(); i++ {
+		fi := t.Field(i)
+		if !fi.IsExported() {
+			continue
+		}
+		xormColumn, field, skip := parseXormTag(fi.Tag.Get(xormTagName))
+		if skip {
+			continue
+		}
+		field.index = i
+
+		found := false
+		for _, tagName := range tagNames {
+			tagName = strings.TrimSpace(tagName)
+			if tagName == "" {
+				continue
+			}
+			if tagName == xormTagName {
+				field.column, found = xormColumn, xormColumn != ""
+			} else {
+				var omitZero bool
+				field.column, omitZero, found = tagColumnName(fi, tagName)
+				field.omitZero = field.omitZero || omitZero
+			}
+			if found {
+				break
+			}
+		}
+		if field.column == "-" {
+			continue
+		}
+		if !found {
+			field.column = convertToByType(fi.Name, convertType)
+		}
+		if field.column != "" {
+			info.fields = append(info.fields, field)
+		}
+	}
+	return info
+}
+
+// structColumnMap 按零值策略获取字段和值，标签为 omitempty 的字段值为零值时总是忽略
+// columns 不为空时只获取这些字段，且这些字段的零值不忽略，nil 仍按策略处理
+func structColumnMap(v reflect.Value, info *structFields, policy ZeroValuePolicy, columns ...string) map[string]any {
+	columnMap := make(map[string]any)
+	for _, field := range info.fields {
+		value := v.Field(field.index)
+		listed := len(columns) > 0
+		if listed && lo.IndexOf(columns, field.column) < 0 {
+			continue
+		}
+		if isNilValue(value.Interface()) {
+			if policy != ZeroValueIncludeAll || (field.omitZero && !listed) {
+				continue
+			}
+			columnMap[field.column] = nil
+			continue
+		}
+		if !listed && value.IsZero() && (field.omitZero || policy == ZeroValueOmitZero) {
+			continue
+		}
+		columnMap[field.column] = value.Interface()
+	}
+	return columnMap
+}
+
+// getTableName 表名，实现了 TableName() 时使用其返回值，in 的值会传给 TableName()
+func (f *structFields) getTableName(v reflect.Value, convertType string) string {
+	if v.IsValid() {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		if namer, ok := ptr.Interface().(tableNamer); ok {
+			return namer.TableName()
+		}
+	}
+	if f.tableName != "" {
+		return f.tableName
+	}
+	return convertToByType(f.structName, convertType)
+}
+
+// autoTimeValue created、updated 字段的当前时间，支持 time.Time 和整数类型的时间戳
+func autoTimeValue(t reflect.Type, now time.Time) (any, bool) {
+	isPtr := t.Kind() == reflect.Ptr
+	if isPtr {
+		t = t.Elem()
+	}
+	var value reflect.Value
+	switch {
+	case t == reflect.TypeOf(time.Time{}):
+		value = reflect.ValueOf(now)
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		value = reflect.New(t).Elem()
+		value.SetInt(now.Unix())
+	case t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uint64:
+		value = reflect.New(t).Elem()
+		value.SetUint(uint64(now.Unix()))
+	default:
+		return nil, false
+	}
+	if isPtr {
+		ptr := reflect.New(t)
+		ptr.Elem().Set(value)
+		return ptr.Interface(), true
+	}
+	return value.Interface(), true
+}
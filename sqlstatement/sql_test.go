@@ -1,10 +1,16 @@
 package sqlstatement_test
 
 import (
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/tianlin0/go-plat-mysql/sqlstatement"
 	"github.com/tianlin0/go-plat-utils/conv"
+	"math"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestGenerateWhereClause(t *testing.T) {
@@ -97,8 +103,8 @@ func TestGenerateWhereClause(t *testing.T) {
 }
 
 type AgeKey struct {
-	Name *string "json:`name`"
-	Age  int     "json:`age`"
+	Name *string `json:"name"`
+	Age  int     `json:"age"`
 }
 
 func TestGenerateWhereClause1(t *testing.T) {
@@ -163,3 +169,1489 @@ func TestInsertSql(t *testing.T) {
 	}, 0, 0)
 	fmt.Println(a, b, e)
 }
+
+func TestGenerateWhereClauseBetween(t *testing.T) {
+	sta := new(sqlstatement.Statement)
+
+	sqlStr, list := sta.GenerateWhereClause(sqlstatement.LogicCondition{
+		Conditions: []any{
+			sqlstatement.Condition{
+				Field:    "id",
+				Operator: "between",
+				Value:    []int{1, 10},
+			},
+			sqlstatement.Condition{
+				Field:    "created_at",
+				Operator: "NOT BETWEEN",
+				Value:    sqlstatement.Range{Start: "2024-01-01", End: "2024-12-31"},
+			},
+			sqlstatement.Condition{
+				Field:    "age",
+				Operator: "BETWEEN",
+				Value:    []int{1, 2, 3},
+			},
+		},
+	})
+	if sqlStr != "(`id` BETWEEN ? AND ?) AND (`created_at` NOT BETWEEN ? AND ?)" {
+		t.Fatalf("unexpected sql: %s", sqlStr)
+	}
+	if conv.String(list) != `[1,10,"2024-01-01","2024-12-31"]` {
+		t.Fatalf("unexpected args: %v", list)
+	}
+
+	sqlStr, list = sta.GenerateWhereClauseByMap(map[string]any{
+		"id": sqlstatement.Range{Start: 5, End: 8},
+	})
+	if sqlStr != "(`id` BETWEEN ? AND ?)" || len(list) != 2 {
+		t.Fatalf("unexpected sql: %s %v", sqlStr, list)
+	}
+
+	sqlStr, list = sta.GenerateWhereClause(sqlstatement.LogicCondition{
+		Conditions: []any{
+			sqlstatement.Condition{Field: "id", Value: sqlstatement.Range{Start: 1, End: 2}},
+			sqlstatement.Condition{Field: "age", Operator: "!=", Value: &sqlstatement.Range{Start: 3, End: 4}},
+		},
+	})
+	if sqlStr != "(`id` BETWEEN ? AND ?) AND (`age` NOT BETWEEN ? AND ?)" || conv.String(list) != "[1,2,3,4]" {
+		t.Fatalf("unexpected sql: %s %v", sqlStr, list)
+	}
+	if _, _, err := sta.GenerateWhereClauseStrict(sqlstatement.LogicCondition{
+		Conditions: []any{sqlstatement.Condition{Field: "id", Operator: ">", Value: sqlstatement.Range{Start: 1, End: 2}}},
+	}); err == nil {
+		t.Fatal("expected error for range with compare operator")
+	}
+	text, err := sqlstatement.FormatFilter(sqlstatement.LogicCondition{
+		Conditions: []any{sqlstatement.Condition{Field: "id", Value: sqlstatement.Range{Start: 1, End: 2}}},
+	})
+	if err != nil || text != "id BETWEEN 1 AND 2" {
+		t.Fatalf("unexpected filter: %s %v", text, err)
+	}
+}
+
+func TestGenerateWhereClauseNull(t *testing.T) {
+	sta := new(sqlstatement.Statement)
+
+	var deletedAt *string
+	sqlStr, list := sta.GenerateWhereClause(sqlstatement.LogicCondition{
+		Conditions: []any{
+			sqlstatement.Condition{Field: "deleted_at", Operator: "IS NULL"},
+			sqlstatement.Condition{Field: "updated_at", Operator: "is not null", Value: "ignore"},
+			sqlstatement.Condition{Field: "name", Operator: "=", Value: nil},
+			sqlstatement.Condition{Field: "age", Operator: "!=", Value: nil},
+			sqlstatement.Condition{Field: "remark", Value: deletedAt},
+			sqlstatement.Condition{Field: "score", Operator: ">", Value: nil},
+		},
+	})
+	expected := "(`deleted_at` IS NULL) AND (`updated_at` IS NOT NULL) AND (`name` IS NULL) AND " +
+		"(`age` IS NOT NULL) AND (`remark` IS NULL)"
+	if sqlStr != expected || len(list) != 0 {
+		t.Fatalf("unexpected sql: %s %v", sqlStr, list)
+	}
+
+	sqlStr, list = sta.GenerateWhereClauseByMap(map[string]any{
+		"deleted_at": nil,
+	})
+	if sqlStr != "(`deleted_at` IS NULL)" || len(list) != 0 {
+		t.Fatalf("unexpected sql: %s %v", sqlStr, list)
+	}
+}
+
+func TestGenerateWhereClauseOperators(t *testing.T) {
+	sta := new(sqlstatement.Statement)
+
+	err := sqlstatement.RegisterOperator(sqlstatement.Operator{
+		Name: "sounds   like",
+		Builder: func(field string, operator string, value any) (string, []any, error) {
+			return fmt.Sprintf("%s %s ?", field, operator), []any{value}, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		con      sqlstatement.Condition
+		expected string
+		args     int
+	}{
+		{sqlstatement.Condition{Field: "a", Operator: "!=", Value: 1}, "(`a` != ?)", 1},
+		{sqlstatement.Condition{Field: "a", Operator: "<>", Value: 1}, "(`a` <> ?)", 1},
+		{sqlstatement.Condition{Field: "a", Operator: "<>", Value: []int{1, 2}}, "(`a` NOT IN (?,?))", 2},
+		{sqlstatement.Condition{Field: "a", Operator: "not  like", Value: "%a"}, "(`a` NOT LIKE ?)", 1},
+		{sqlstatement.Condition{Field: "a", Operator: "REGEXP", Value: "^a"}, "(`a` REGEXP ?)", 1},
+		{sqlstatement.Condition{Field: "a", Operator: "not regexp", Value: "^a"}, "(`a` NOT REGEXP ?)", 1},
+		{sqlstatement.Condition{Field: "a", Operator: "LIKE BINARY", Value: "A%"}, "(`a` LIKE BINARY ?)", 1},
+		{sqlstatement.Condition{Field: "a", Operator: "SOUNDS LIKE", Value: "abc"}, "(`a` SOUNDS LIKE ?)", 1},
+		{sqlstatement.Condition{Field: "a", Operator: "UNKNOWN", Value: "abc"}, "", 0},
+		{sqlstatement.Condition{Field: "a", Operator: "NOT LIKE", Value: []string{"keep%"}}, "", 0},
+		{sqlstatement.Condition{Field: "a", Operator: "not regexp", Value: []string{"^a"}}, "", 0},
+		{sqlstatement.Condition{Field: "a", Operator: "NOT LIKE BINARY", Value: []string{"A%"}}, "", 0},
+		{sqlstatement.Condition{Field: "a", Operator: ">", Value: []int{1, 2}}, "", 0},
+	}
+	for _, one := range tests {
+		sqlStr, list := sta.GenerateWhereClause(sqlstatement.LogicCondition{Conditions: []any{one.con}})
+		if sqlStr != one.expected || len(list) != one.args {
+			t.Errorf("%s: unexpected sql: %s %v", one.con.Operator, sqlStr, list)
+		}
+	}
+
+	notLike := sqlstatement.LogicCondition{Conditions: []any{sqlstatement.Condition{Field: "name", Operator: "NOT LIKE", Value: []string{"keep%"}}}}
+	if _, _, err = sta.GenerateWhereClauseStrict(notLike); err == nil || !strings.Contains(err.Error(), "operator NOT LIKE does not accept a list") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if sqlStr, _ := sta.DeleteSqlByWhereCondition("t", []string{"name"}, notLike); sqlStr != "" {
+		t.Errorf("unexpected sql: %s", sqlStr)
+	}
+}
+
+func TestGenerateWhereClauseStrict(t *testing.T) {
+	sta := new(sqlstatement.Statement)
+
+	group := sqlstatement.LogicCondition{
+		Conditions: []any{
+			sqlstatement.Condition{Field: "name", Operator: "=", Value: "test"},
+			sqlstatement.Condition{Field: "age", Operator: "=>", Value: 18},
+			sqlstatement.Condition{Field: "id", Operator: "IN", Value: []int{}},
+			sqlstatement.Condition{Field: "nickname", Operator: "=", Value: "a"},
+			"1=1",
+		},
+	}
+	sqlStr, list := sta.GenerateWhereClause(group)
+	if sqlStr != "(`name` = ?) AND (`nickname` = ?)" || len(list) != 2 {
+		t.Fatalf("unexpected sql: %s %v", sqlStr, list)
+	}
+
+	_, _, err := sta.GenerateWhereClauseStrict(group, "name", "age", "id")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, one := range []string{"=>", "list is empty", "nickname", "string"} {
+		if !strings.Contains(err.Error(), one) {
+			t.Errorf("error should contain %s: %s", one, err.Error())
+		}
+	}
+
+	sqlStr, list, err = sta.GenerateWhereClauseStrict(sqlstatement.LogicCondition{
+		Conditions: []any{
+			sqlstatement.Condition{Field: "name", Operator: "=", Value: "test"},
+		},
+	}, "`name`")
+	if err != nil || sqlStr != "(`name` = ?)" || len(list) != 1 {
+		t.Fatalf("unexpected sql: %s %v %v", sqlStr, list, err)
+	}
+}
+
+func TestSqlStructStrictMode(t *testing.T) {
+	sqlObj := sqlstatement.NewSqlStruct(sqlstatement.SetStructData(&AgeKey{}), sqlstatement.SetStrictMode(true),
+		sqlstatement.SetColumnTagName("json"))
+
+	_, _, err := sqlObj.DeleteSql(sqlstatement.LogicCondition{
+		Conditions: []any{
+			sqlstatement.Condition{Field: "nmae", Operator: "=", Value: "test"},
+		},
+	})
+	if err == nil {
+		t.Fatal("expected error for unknown field")
+	}
+
+	_, _, err = sqlObj.DeleteSqlByMap(map[string]any{"name": []string{}})
+	if err == nil {
+		t.Fatal("expected error for empty list")
+	}
+
+	sqlStr, list, err := sqlObj.SelectSqlByMap("", map[string]any{"name": "test"}, 0, 0)
+	if err != nil || sqlStr != "SELECT * FROM `age_key` WHERE (`name` = ?)" || len(list) != 1 {
+		t.Fatalf("unexpected sql: %s %v %v", sqlStr, list, err)
+	}
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		hasError bool
+	}{
+		{"user_id", "`user_id`", false},
+		{"`user_id`", "`user_id`", false},
+		{"t.user_id", "`t`.`user_id`", false},
+		{"db.t.user_id", "`db`.`t`.`user_id`", false},
+		{"t.*", "`t`.*", false},
+		{"用户", "`用户`", false},
+		{"*", "", true},
+		{"a.b.c.d", "", true},
+		{"t.", "", true},
+		{"user id", "", true},
+		{"id;drop table t", "", true},
+		{"name'", "", true},
+	}
+	for _, one := range tests {
+		quoted, err := sqlstatement.QuoteIdentifier(one.name)
+		if (err != nil) != one.hasError || quoted != one.expected {
+			t.Errorf("%s: unexpected %s %v", one.name, quoted, err)
+		}
+	}
+
+	quoted, err := sqlstatement.QuoteIdentifierWithAlias("t.user_id AS uid")
+	if err != nil || quoted != "`t`.`user_id` AS `uid`" {
+		t.Errorf("unexpected %s %v", quoted, err)
+	}
+	quoted, err = sqlstatement.QuoteIdentifierWithAlias("t.user_id uid")
+	if err != nil || quoted != "`t`.`user_id` AS `uid`" {
+		t.Errorf("unexpected %s %v", quoted, err)
+	}
+	if _, err = sqlstatement.QuoteIdentifierWithAlias("t.user_id AS u id"); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestStatementIdentifier(t *testing.T) {
+	sta := new(sqlstatement.Statement)
+	allColumns := []string{"id", "name"}
+
+	sqlStr, _ := sta.SelectSql("db.user", allColumns, "id, name AS n, password", map[string]any{"id": 1}, 0, 10)
+	if sqlStr != "SELECT `id`, `name` AS `n` FROM `db`.`user` WHERE (`id` = ?) LIMIT 0, 10" {
+		t.Errorf("unexpected sql: %s", sqlStr)
+	}
+
+	for _, selectStr := range []string{"password, (select 1)", "id, (select 1)", "password", "SUM(1)", "COUNT(DISTINCT 1)"} {
+		if sqlStr, _, err := sta.SelectSqlE("user", allColumns, selectStr, nil, 0, 0); sqlStr != "" || err == nil {
+			t.Errorf("unexpected sql: %s %v", sqlStr, err)
+		}
+	}
+	sqlStr, _ = sta.SelectSql("user", allColumns, "COUNT(1) AS cnt", nil, 0, 0)
+	if sqlStr != "SELECT COUNT(1) AS `cnt` FROM `user`" {
+		t.Errorf("unexpected sql: %s", sqlStr)
+	}
+	sqlObj := sqlstatement.NewSqlStruct(sqlstatement.SetStructData(&UserInfo{}), sqlstatement.SetColumnTagName("json"))
+	if sqlStr, _, err := sqlObj.SelectSql("count(1)", sqlstatement.LogicCondition{}, 0, 0); err != nil || sqlStr != "SELECT COUNT(1) FROM `user_info`" {
+		t.Errorf("unexpected sql: %s %v", sqlStr, err)
+	}
+
+	sqlStr, _ = sta.InsertSql("user;drop", allColumns, map[string]any{"id": 1})
+	if sqlStr != "" {
+		t.Errorf("unexpected sql: %s", sqlStr)
+	}
+
+	sqlStr, list := sta.GenerateWhereClause(sqlstatement.LogicCondition{
+		Conditions: []any{
+			sqlstatement.Condition{Field: "t.user_id", Operator: "=", Value: 1},
+			sqlstatement.Condition{Field: "name` = 1 OR `1", Operator: "=", Value: 1},
+		},
+	})
+	if sqlStr != "(`t`.`user_id` = ?)" || len(list) != 1 {
+		t.Errorf("unexpected sql: %s", sqlStr)
+	}
+}
+
+func TestGenerateWhereClauseMatch(t *testing.T) {
+	sta := new(sqlstatement.Statement)
+
+	tests := []struct {
+		con      sqlstatement.Condition
+		expected string
+		arg      string
+	}{
+		{sqlstatement.Condition{Field: "name", Operator: "contains", Value: "ab"}, "(`name` LIKE ? ESCAPE '/')", "%ab%"},
+		{sqlstatement.Condition{Field: "name", Operator: "CONTAINS", Value: `a\b`}, "(`name` LIKE ? ESCAPE '/')", `%a\b%`},
+		{sqlstatement.Condition{Field: "name", Operator: "ENDS_WITH", Value: `abc\`}, "(`name` LIKE ? ESCAPE '/')", `%abc\`},
+		{sqlstatement.Condition{Field: "name", Operator: "CONTAINS", Value: "/&#@^$!_"}, "(`name` LIKE ? ESCAPE '/')", "%//&#@^$!/_%"},
+		{sqlstatement.Condition{Field: "name", Operator: "STARTS_WITH", Value: "50%"}, "(`name` LIKE ? ESCAPE '/')", "50/%%"},
+		{sqlstatement.Condition{Field: "name", Operator: "ENDS_WITH", Value: "a_/b"}, "(`name` LIKE ? ESCAPE '&')", "%a&_/b"},
+		{sqlstatement.Condition{Field: "name", Operator: "LIKE", Value: "a%"}, "(`name` LIKE ?)", "a%"},
+	}
+	for _, one := range tests {
+		sqlStr, list := sta.GenerateWhereClause(sqlstatement.LogicCondition{Conditions: []any{one.con}})
+		if sqlStr != one.expected || len(list) != 1 || list[0] != one.arg {
+			t.Errorf("%s: unexpected sql: %s %v", one.con.Operator, sqlStr, list)
+		}
+	}
+
+	sqlStr, list := sta.GenerateWhereClause(sqlstatement.LogicCondition{Conditions: []any{
+		sqlstatement.Condition{Field: "name", Operator: "CONTAINS", Value: []string{"a_", "b/"}},
+	}})
+	if sqlStr != "(`name` LIKE ? ESCAPE '/' OR `name` LIKE ? ESCAPE '&')" || conv.String(list) != `["%a/_%","%b/%"]` {
+		t.Errorf("unexpected sql: %s %v", sqlStr, list)
+	}
+	if _, _, err := sta.GenerateWhereClauseStrict(sqlstatement.LogicCondition{Conditions: []any{
+		sqlstatement.Condition{Field: "name", Operator: "STARTS_WITH", Value: []string{}},
+	}}); err == nil {
+		t.Error("expected error for empty list")
+	}
+}
+
+type UserInfo struct {
+	Id       int64   `json:"id"`
+	Name     string  `json:"name"`
+	Age      int     `json:"age"`
+	Nickname *string `json:"nickname"`
+}
+
+func TestStatementDeterministicSql(t *testing.T) {
+	sta := new(sqlstatement.Statement)
+	allColumns := []string{"id", "name", "age", "status"}
+
+	tests := []struct {
+		name     string
+		build    func() (string, []any)
+		expected string
+		args     string
+	}{
+		{
+			name: "where map",
+			build: func() (string, []any) {
+				return sta.GenerateWhereClauseByMap(map[string]any{"status": []int{1, 2}, "age": 18, "name": "a"})
+			},
+			expected: "(`age` = ?) AND (`name` = ?) AND (`status` IN (?,?))",
+			args:     `[18,"a",1,2]`,
+		},
+		{
+			name: "insert",
+			build: func() (string, []any) {
+				return sta.InsertSql("user", allColumns, map[string]any{"status": 1, "name": "a", "age": 18, "other": 1})
+			},
+			expected: "INSERT INTO `user` SET `age`=?,`name`=?,`status`=?",
+			args:     `[18,"a",1]`,
+		},
+		{
+			name: "update",
+			build: func() (string, []any) {
+				return sta.UpdateSql("user", allColumns, map[string]any{"status": 1, "name": "a"}, map[string]any{"id": 1, "age": 18})
+			},
+			expected: "UPDATE `user` SET `name`=?,`status`=? WHERE (`age` = ?) AND (`id` = ?)",
+			args:     `["a",1,18,1]`,
+		},
+		{
+			name: "select",
+			build: func() (string, []any) {
+				return sta.SelectSql("user", allColumns, "", map[string]any{"status": 1, "name": "a"}, 10, 20)
+			},
+			expected: "SELECT * FROM `user` WHERE (`name` = ?) AND (`status` = ?) LIMIT 10, 20",
+			args:     `["a",1]`,
+		},
+		{
+			name: "delete",
+			build: func() (string, []any) {
+				return sta.DeleteSql("user", allColumns, map[string]any{"status": 1, "id": []int{1, 2}})
+			},
+			expected: "DELETE FROM `user` WHERE (`id` IN (?,?)) AND (`status` = ?)",
+			args:     `[1,2,1]`,
+		},
+	}
+	for _, one := range tests {
+		for i := 0; i < 10; i++ {
+			sqlStr, list := one.build()
+			if sqlStr != one.expected || conv.String(list) != one.args {
+				t.Fatalf("%s: unexpected sql: %s %s", one.name, sqlStr, conv.String(list))
+			}
+		}
+	}
+}
+
+func TestSqlStructDeterministicSql(t *testing.T) {
+	sqlObj := sqlstatement.NewSqlStruct(sqlstatement.SetStructData(&UserInfo{}), sqlstatement.SetColumnTagName("json"))
+	nickname := "n"
+
+	tests := []struct {
+		name     string
+		build    func() (string, []any, error)
+		expected string
+		args     string
+	}{
+		{
+			name: "insert",
+			build: func() (string, []any, error) {
+				return sqlObj.InsertSql(&UserInfo{Id: 1, Name: "a", Age: 18, Nickname: &nickname})
+			},
+			expected: "INSERT INTO `user_info` (`age`,`id`,`name`,`nickname`) VALUES (?,?,?,?)",
+			args:     `[18,1,"a","n"]`,
+		},
+		{
+			name: "insert by map",
+			build: func() (string, []any, error) {
+				return sqlObj.InsertSqlByMap(map[string]any{"nickname": "n", "name": "a", "age": 18})
+			},
+			expected: "INSERT INTO `user_info` SET `age`=?,`name`=?,`nickname`=?",
+			args:     `[18,"a","n"]`,
+		},
+		{
+			name: "update",
+			build: func() (string, []any, error) {
+				return sqlObj.UpdateSql(&UserInfo{Id: 1, Name: "a", Age: 18}, []string{"name", "age"}, sqlstatement.LogicCondition{
+					Conditions: []any{sqlstatement.Condition{Field: "id", Operator: "=", Value: 1}},
+				})
+			},
+			expected: "UPDATE `user_info` SET `age` = ?, `name` = ? WHERE (`id` = ?)",
+			args:     `[18,"a",1]`,
+		},
+		{
+			name: "update by map",
+			build: func() (string, []any, error) {
+				return sqlObj.UpdateSqlWithUpdateMap(map[string]any{"name": "a", "age": 18}, map[string]any{"name": "b", "id": 1})
+			},
+			expected: "UPDATE `user_info` SET `age`=?,`name`=? WHERE (`id` = ?) AND (`name` = ?)",
+			args:     `[18,"a",1,"b"]`,
+		},
+		{
+			name: "select by map",
+			build: func() (string, []any, error) {
+				return sqlObj.SelectSqlByMap("name,id", map[string]any{"name": "b", "age": 1}, 0, 10)
+			},
+			expected: "SELECT `name`, `id` FROM `user_info` WHERE (`age` = ?) AND (`name` = ?) LIMIT 0, 10",
+			args:     `[1,"b"]`,
+		},
+		{
+			name: "delete by map",
+			build: func() (string, []any, error) {
+				return sqlObj.DeleteSqlByMap(map[string]any{"name": "b", "id": 1})
+			},
+			expected: "DELETE FROM `user_info` WHERE (`id` = ?) AND (`name` = ?)",
+			args:     `[1,"b"]`,
+		},
+	}
+	for _, one := range tests {
+		for i := 0; i < 10; i++ {
+			sqlStr, list, err := one.build()
+			if err != nil || sqlStr != one.expected || conv.String(list) != one.args {
+				t.Fatalf("%s: unexpected sql: %s %s %v", one.name, sqlStr, conv.String(list), err)
+			}
+		}
+	}
+}
+
+func TestSelectSqlClause(t *testing.T) {
+	sta := new(sqlstatement.Statement)
+	allColumns := []string{"id", "name", "age", "status"}
+
+	sqlStr, list := sta.SelectSql("user", allColumns, "status, COUNT(*) AS cnt", map[string]any{"age": 18}, 0, 10,
+		sqlstatement.SetGroupBy("status", "unknown"),
+		sqlstatement.SetHaving(sqlstatement.LogicCondition{
+			Conditions: []any{sqlstatement.Condition{Field: "cnt", Operator: ">", Value: 1}},
+		}),
+		sqlstatement.SetOrderBy(sqlstatement.Desc("cnt"), sqlstatement.Asc("status"), sqlstatement.Asc("password")))
+	expected := "SELECT `status`, COUNT(*) AS `cnt` FROM `user` WHERE (`age` = ?) GROUP BY `status` HAVING (`cnt` > ?) " +
+		"ORDER BY `cnt` DESC, `status` ASC LIMIT 0, 10"
+	if sqlStr != expected || conv.String(list) != "[18,1]" {
+		t.Errorf("unexpected sql: %s %v", sqlStr, list)
+	}
+
+	sqlStr, list = sta.SelectSqlByWhereCondition("user", allColumns, "", sqlstatement.LogicCondition{}, 20, 10,
+		sqlstatement.SetOrderBy(sqlstatement.Desc("id")))
+	if sqlStr != "SELECT * FROM `user` ORDER BY `id` DESC LIMIT 20, 10" || len(list) != 0 {
+		t.Errorf("unexpected sql: %s %v", sqlStr, list)
+	}
+
+	sqlObj := sqlstatement.NewSqlStruct(sqlstatement.SetStructData(&UserInfo{}), sqlstatement.SetColumnTagName("json"))
+	sqlStr, list, err := sqlObj.SelectSql("age, COUNT(*) AS cnt", sqlstatement.LogicCondition{
+		Conditions: []any{sqlstatement.Condition{Field: "name", Operator: "=", Value: "a"}},
+	}, 0, 10, sqlstatement.SetGroupBy("age"), sqlstatement.SetHaving(sqlstatement.LogicCondition{
+		Conditions: []any{sqlstatement.Condition{Field: "cnt", Operator: ">=", Value: 2}},
+	}), sqlstatement.SetOrderBy(sqlstatement.Desc("age")))
+	expected = "SELECT `age`, COUNT(*) AS `cnt` FROM `user_info` WHERE (`name` = ?) GROUP BY `age` HAVING (`cnt` >= ?) " +
+		"ORDER BY `age` DESC LIMIT 10 OFFSET 0"
+	if err != nil || sqlStr != expected || conv.String(list) != `["a",2]` {
+		t.Errorf("unexpected sql: %s %v %v", sqlStr, list, err)
+	}
+
+	sqlObj = sqlstatement.NewSqlStruct(sqlstatement.SetStructData(&UserInfo{}), sqlstatement.SetColumnTagName("json"),
+		sqlstatement.SetStrictMode(true))
+	if _, _, err = sqlObj.SelectSqlByMap("", nil, 0, 10, sqlstatement.SetOrderBy(sqlstatement.Asc("password"))); err == nil {
+		t.Error("expected error for unknown order field")
+	}
+
+	having := sqlstatement.SetHaving(sqlstatement.LogicCondition{
+		Conditions: []any{
+			sqlstatement.Condition{Field: "secret", Operator: ">", Value: 1},
+			sqlstatement.Condition{Field: "status", Operator: "=", Value: sqlstatement.Column("password")},
+			sqlstatement.Condition{Field: "status", Operator: ">", Value: 0},
+		},
+	})
+	sqlStr, list = sta.SelectSql("user", allColumns, "status", nil, 0, 0, sqlstatement.SetGroupBy("status"), having)
+	if sqlStr != "SELECT `status` FROM `user` GROUP BY `status` HAVING (`status` > ?)" || conv.String(list) != "[0]" {
+		t.Errorf("unexpected sql: %s %v", sqlStr, list)
+	}
+	reject := sqlstatement.NewStatement(sqlstatement.SetStatementColumnPolicy(sqlstatement.ColumnPolicyReject))
+	if sqlStr, _ = reject.SelectSql("user", allColumns, "status", nil, 0, 0, sqlstatement.SetGroupBy("status"), having); sqlStr != "" {
+		t.Errorf("unexpected sql: %s", sqlStr)
+	}
+	sqlObj = sqlstatement.NewSqlStruct(sqlstatement.SetStructData(&UserInfo{}), sqlstatement.SetColumnTagName("json"),
+		sqlstatement.SetColumnPolicy(sqlstatement.ColumnPolicyReject))
+	if _, _, err = sqlObj.SelectSql("age", sqlstatement.LogicCondition{}, 0, 0, sqlstatement.SetGroupBy("age"), having); err == nil {
+		t.Error("expected error for unknown having field")
+	}
+	if _, _, err = sqlObj.SelectSqlByMap("age", nil, 0, 0, sqlstatement.SetGroupBy("age"), having); err == nil {
+		t.Error("expected error for unknown having field")
+	}
+}
+
+func TestSelectSqlJoin(t *testing.T) {
+	sta := new(sqlstatement.Statement)
+	opts := []sqlstatement.SelectOption{
+		sqlstatement.SetTableAlias("u"),
+		sqlstatement.SetJoin(sqlstatement.Join{
+			Type:    "left",
+			Table:   "order",
+			Alias:   "o",
+			Columns: []string{"id", "user_id", "amount"},
+			On: sqlstatement.LogicCondition{
+				Conditions: []any{
+					sqlstatement.Condition{Field: "o.user_id", Operator: "=", Value: sqlstatement.Column("u.id")},
+					sqlstatement.Condition{Field: "o.amount", Operator: ">", Value: 0},
+				},
+			},
+		}),
+		sqlstatement.SetOrderBy(sqlstatement.Desc("o.amount")),
+	}
+
+	sqlStr, list := sta.SelectSql("user", []string{"id", "name"}, "u.name, o.amount AS amt, o.password",
+		map[string]any{"u.name": "a", "o.id": 1, "o.password": "p"}, 0, 10, opts...)
+	expected := "SELECT `u`.`name`, `o`.`amount` AS `amt` FROM `user` AS `u` LEFT JOIN `order` AS `o` " +
+		"ON (`o`.`user_id` = `u`.`id`) AND (`o`.`amount` > ?) WHERE (`o`.`id` = ?) AND (`u`.`name` = ?) " +
+		"ORDER BY `o`.`amount` DESC LIMIT 0, 10"
+	if sqlStr != expected || conv.String(list) != `[0,1,"a"]` {
+		t.Errorf("unexpected sql: %s %v", sqlStr, list)
+	}
+
+	sqlObj := sqlstatement.NewSqlStruct(sqlstatement.SetStructData(&UserInfo{}), sqlstatement.SetColumnTagName("json"),
+		sqlstatement.SetStrictMode(true))
+	sqlStr, list, err := sqlObj.SelectSql("u.*, o.amount", sqlstatement.LogicCondition{
+		Conditions: []any{sqlstatement.Condition{Field: "o.amount", Operator: ">=", Value: 10}},
+	}, 0, 0, opts...)
+	expected = "SELECT `u`.*, `o`.`amount` FROM `user_info` AS `u` LEFT JOIN `order` AS `o` " +
+		"ON (`o`.`user_id` = `u`.`id`) AND (`o`.`amount` > ?) WHERE (`o`.`amount` >= ?) ORDER BY `o`.`amount` DESC"
+	if err != nil || sqlStr != expected || conv.String(list) != `[0,10]` {
+		t.Errorf("unexpected sql: %s %v %v", sqlStr, list, err)
+	}
+
+	_, _, err = sqlObj.SelectSql("", sqlstatement.LogicCondition{
+		Conditions: []any{sqlstatement.Condition{Field: "o.password", Operator: "=", Value: 1}},
+	}, 0, 0, opts...)
+	if err == nil {
+		t.Error("expected error for unknown joined column")
+	}
+
+	badOn := sqlstatement.SetJoin(sqlstatement.Join{
+		Table: "order", Alias: "o", Columns: []string{"user_id"},
+		On: sqlstatement.LogicCondition{
+			Conditions: []any{sqlstatement.Condition{Field: "o.bogus", Operator: "=", Value: sqlstatement.Column("u.nope")}},
+		},
+	})
+	if sqlStr, _ = sta.SelectSql("user", []string{"id"}, "", nil, 0, 0, sqlstatement.SetTableAlias("u"), badOn); sqlStr != "" {
+		t.Errorf("unexpected sql: %s", sqlStr)
+	}
+	reject := sqlstatement.NewStatement(sqlstatement.SetStatementColumnPolicy(sqlstatement.ColumnPolicyReject))
+	badValue := sqlstatement.SetJoin(sqlstatement.Join{
+		Table: "order", Alias: "o", Columns: []string{"user_id"},
+		On: sqlstatement.LogicCondition{
+			Conditions: []any{
+				sqlstatement.Condition{Field: "o.user_id", Operator: "=", Value: sqlstatement.Column("u.id")},
+				sqlstatement.Condition{Field: "o.user_id", Operator: "=", Value: sqlstatement.Column("u.nope")},
+			},
+		},
+	})
+	if sqlStr, _ = reject.SelectSql("user", []string{"id"}, "", nil, 0, 0, sqlstatement.SetTableAlias("u"), badValue); sqlStr != "" {
+		t.Errorf("unexpected sql: %s", sqlStr)
+	}
+	sqlStr, _ = sta.SelectSql("user", []string{"id"}, "", nil, 0, 0, sqlstatement.SetTableAlias("u"), badValue)
+	if sqlStr != "SELECT * FROM `user` AS `u` INNER JOIN `order` AS `o` ON (`o`.`user_id` = `u`.`id`)" {
+		t.Errorf("unexpected sql: %s", sqlStr)
+	}
+
+	sqlStr, _ = sta.SelectSql("user", []string{"id"}, "", nil, 0, 0, sqlstatement.SetJoin(sqlstatement.Join{
+		Type: "CROSS", Table: "order",
+	}))
+	if sqlStr != "" {
+		t.Errorf("unexpected sql: %s", sqlStr)
+	}
+}
+
+func TestGenerateWhereClauseSubQuery(t *testing.T) {
+	sta := new(sqlstatement.Statement)
+
+	sub := sqlstatement.NewSubQuery(sta.SelectSql("user", []string{"id", "status"}, "id", map[string]any{"status": 1}, 0, 0))
+	exists := sqlstatement.NewSubQuery(sta.SelectSqlByWhereCondition("order", []string{"user_id", "amount"}, "user_id",
+		sqlstatement.LogicCondition{
+			Conditions: []any{
+				sqlstatement.Condition{Field: "order.user_id", Operator: "=", Value: sqlstatement.Column("t.user_id")},
+				sqlstatement.Condition{Field: "order.amount", Operator: ">", Value: 100},
+			},
+		}, 0, 0))
+
+	sqlStr, list := sta.GenerateWhereClause(sqlstatement.LogicCondition{
+		Conditions: []any{
+			sqlstatement.Condition{Field: "type", Operator: "=", Value: "a"},
+			sqlstatement.Condition{Field: "user_id", Operator: "IN", Value: sub},
+			sqlstatement.Exists{Query: exists, Not: true},
+			sqlstatement.Condition{Field: "age", Operator: ">", Value: 18},
+		},
+	})
+	expected := "(`type` = ?) AND (`user_id` IN (SELECT `id` FROM `user` WHERE (`status` = ?))) AND " +
+		"(NOT EXISTS (SELECT `user_id` FROM `order` WHERE (`order`.`user_id` = `t`.`user_id`) AND (`order`.`amount` > ?))) AND " +
+		"(`age` > ?)"
+	if sqlStr != expected || conv.String(list) != `["a",1,100,18]` {
+		t.Errorf("unexpected sql: %s %v", sqlStr, list)
+	}
+
+	_, _, err := sta.GenerateWhereClauseStrict(sqlstatement.LogicCondition{
+		Conditions: []any{sqlstatement.Exists{}},
+	})
+	if err == nil {
+		t.Error("expected error for empty sub query")
+	}
+}
+
+func TestGenerateWhereClauseExprAndNot(t *testing.T) {
+	sta := new(sqlstatement.Statement)
+
+	sqlStr, list := sta.GenerateWhereClause(sqlstatement.LogicCondition{
+		Conditions: []any{
+			sqlstatement.Condition{Field: "status", Operator: "=", Value: 1},
+			sqlstatement.NewExpr("DATE(created_at) = ?", "2024-01-01"),
+			sqlstatement.Not{Condition: sqlstatement.LogicCondition{
+				Operator: "OR",
+				Conditions: []any{
+					sqlstatement.NewExpr("a + b > ?", 10),
+					sqlstatement.Not{Condition: sqlstatement.Condition{Field: "name", Operator: "LIKE", Value: "a%"}},
+				},
+			}},
+			sqlstatement.NewExpr("remark != '?' AND age > ?", 18),
+			sqlstatement.NewExpr("age > ? AND age < ?", 1),
+		},
+	})
+	expected := "(`status` = ?) AND (DATE(created_at) = ?) AND (NOT ((a + b > ?) OR (NOT (`name` LIKE ?)))) AND " +
+		"(remark != '?' AND age > ?)"
+	if sqlStr != expected || conv.String(list) != `[1,"2024-01-01",10,"a%",18]` {
+		t.Errorf("unexpected sql: %s %v", sqlStr, list)
+	}
+
+	_, _, err := sta.GenerateWhereClauseStrict(sqlstatement.LogicCondition{
+		Conditions: []any{
+			sqlstatement.Not{Condition: sqlstatement.Condition{Field: "name", Operator: "=>", Value: 1}},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "=>") {
+		t.Errorf("expected error, got %v", err)
+	}
+}
+
+func TestBatchInsertSql(t *testing.T) {
+	sta := new(sqlstatement.Statement)
+	allColumns := []string{"id", "name", "age"}
+
+	list := sta.BatchInsertSql("user", allColumns, []map[string]any{
+		{"name": "a", "age": 1, "other": 1},
+		{"name": "b", "id": 2},
+		{"name": "c", "age": 3},
+	})
+	if len(list) != 1 || list[0].Sql != "INSERT INTO `user` (`age`,`id`,`name`) VALUES (?,DEFAULT,?),(DEFAULT,?,?),(?,DEFAULT,?)" ||
+		conv.String(list[0].Args) != `[1,"a",2,"b",3,"c"]` {
+		t.Errorf("unexpected sql: %s", conv.String(list))
+	}
+
+	rows := make([]map[string]any, 0)
+	for i := 0; i < 5; i++ {
+		rows = append(rows, map[string]any{"id": i, "name": "n"})
+	}
+	list = sta.BatchInsertSql("user", allColumns, rows, sqlstatement.SetMaxPlaceholders(4))
+	if len(list) != 3 || list[2].Sql != "INSERT INTO `user` (`id`,`name`) VALUES (?,?)" || len(list[0].Args) != 4 {
+		t.Errorf("unexpected sql: %s", conv.String(list))
+	}
+
+	list = sta.BatchInsertSql("user", allColumns, rows, sqlstatement.SetMaxPacketSize(100))
+	if len(list) != 3 {
+		t.Errorf("unexpected sql: %s", conv.String(list))
+	}
+
+	sqlObj := sqlstatement.NewSqlStruct(sqlstatement.SetColumnTagName("json"))
+	nickname := "n"
+	batchList, err := sqlObj.BatchInsertSql([]*UserInfo{
+		{Id: 1, Name: "a", Age: 1, Nickname: &nickname},
+		{Id: 2, Name: "b", Age: 2},
+	})
+	if err != nil || len(batchList) != 1 ||
+		batchList[0].Sql != "INSERT INTO `user_info` (`age`,`id`,`name`,`nickname`) VALUES (?,?,?,?),(?,?,?,DEFAULT)" ||
+		conv.String(batchList[0].Args) != `[1,1,"a","n",2,2,"b"]` {
+		t.Errorf("unexpected sql: %s %v", conv.String(batchList), err)
+	}
+
+	if _, err = sqlObj.BatchInsertSql([]any{&UserInfo{}, &AgeKey{}}); err == nil {
+		t.Error("expected error for mixed rows")
+	}
+}
+
+func TestUpsertSql(t *testing.T) {
+	sta := new(sqlstatement.Statement)
+	allColumns := []string{"id", "name", "cnt", "updated_at"}
+	insertMap := map[string]any{"id": 1, "name": "a", "cnt": 1}
+
+	testCases := []struct {
+		upsert  sqlstatement.Upsert
+		sql     string
+		argList string
+	}{
+		{
+			upsert:  sqlstatement.Upsert{Increments: []string{"cnt"}},
+			sql:     "INSERT INTO `user` (`cnt`,`id`,`name`) VALUES (?,?,?) ON DUPLICATE KEY UPDATE `id` = VALUES(`id`), `name` = VALUES(`name`), `cnt` = `cnt` + VALUES(`cnt`)",
+			argList: `[1,1,"a"]`,
+		},
+		{
+			upsert:  sqlstatement.Upsert{UpdateColumns: []string{"name"}, RowAlias: "new"},
+			sql:     "INSERT INTO `user` (`cnt`,`id`,`name`) VALUES (?,?,?) AS `new` ON DUPLICATE KEY UPDATE `name` = `new`.`name`",
+			argList: `[1,1,"a"]`,
+		},
+		{
+			upsert: sqlstatement.Upsert{UpdateColumns: []string{"name"},
+				Exprs: map[string]sqlstatement.Expr{"updated_at": sqlstatement.NewExpr("NOW()"), "cnt": sqlstatement.NewExpr("`cnt` + ?", 2)}},
+			sql:     "INSERT INTO `user` (`cnt`,`id`,`name`) VALUES (?,?,?) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`), `cnt` = `cnt` + ?, `updated_at` = NOW()",
+			argList: `[1,1,"a",2]`,
+		},
+		{
+			upsert:  sqlstatement.Upsert{Mode: sqlstatement.UpsertIgnore},
+			sql:     "INSERT IGNORE INTO `user` (`cnt`,`id`,`name`) VALUES (?,?,?)",
+			argList: `[1,1,"a"]`,
+		},
+		{
+			upsert:  sqlstatement.Upsert{Mode: sqlstatement.UpsertReplace},
+			sql:     "REPLACE INTO `user` (`cnt`,`id`,`name`) VALUES (?,?,?)",
+			argList: `[1,1,"a"]`,
+		},
+		{
+			upsert: sqlstatement.Upsert{UpdateColumns: []string{"updated_at"}},
+			sql:    "",
+		},
+		{
+			upsert: sqlstatement.Upsert{Mode: "MERGE"},
+			sql:    "",
+		},
+	}
+	for _, one := range testCases {
+		sqlStr, args := sta.UpsertSql("user", allColumns, insertMap, one.upsert)
+		if sqlStr != one.sql || (one.sql != "" && conv.String(args) != one.argList) {
+			t.Errorf("unexpected sql: %s %s", sqlStr, conv.String(args))
+		}
+	}
+
+	sqlStr, _ := sta.UpsertSql("user", allColumns, insertMap, sqlstatement.Upsert{ConflictColumns: []string{"id"}})
+	if sqlStr != "INSERT INTO `user` (`cnt`,`id`,`name`) VALUES (?,?,?) ON DUPLICATE KEY UPDATE `cnt` = VALUES(`cnt`), `name` = VALUES(`name`)" {
+		t.Errorf("unexpected sql: %s", sqlStr)
+	}
+	if _, _, err := sta.UpsertSqlE("user", allColumns, insertMap, sqlstatement.Upsert{UpdateColumns: []string{"updated_at"}}); err == nil ||
+		!strings.Contains(err.Error(), "updated_at") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	list := sta.BatchUpsertSql("user", allColumns, []map[string]any{
+		{"id": 1, "name": "a"},
+		{"id": 2, "name": "b"},
+	}, sqlstatement.Upsert{UpdateColumns: []string{"name"}})
+	if len(list) != 1 || list[0].Sql != "INSERT INTO `user` (`id`,`name`) VALUES (?,?),(?,?) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`)" {
+		t.Errorf("unexpected sql: %s", conv.String(list))
+	}
+
+	sqlObj := sqlstatement.NewSqlStruct(sqlstatement.SetColumnTagName("json"))
+	sqlStr, args, err := sqlObj.UpsertSql(&UserInfo{Id: 1, Name: "a", Age: 2}, sqlstatement.Upsert{UpdateColumns: []string{"name", "age"}})
+	if err != nil || sqlStr != "INSERT INTO `user_info` (`age`,`id`,`name`) VALUES (?,?,?) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`), `age` = VALUES(`age`)" ||
+		conv.String(args) != `[2,1,"a"]` {
+		t.Errorf("unexpected sql: %s %s %v", sqlStr, conv.String(args), err)
+	}
+}
+
+func TestKeysetSelectSql(t *testing.T) {
+	sta := new(sqlstatement.Statement)
+	allColumns := []string{"id", "name", "age", "created_at"}
+	where := sqlstatement.LogicCondition{
+		Conditions: []any{sqlstatement.Condition{Field: "name", Operator: "=", Value: "a"}},
+	}
+
+	sortKeys := []sqlstatement.OrderBy{sqlstatement.Asc("age"), sqlstatement.Asc("id")}
+	sqlStr, list := sta.KeysetSelectSql("user", allColumns, "", where, sqlstatement.KeysetPage{SortKeys: sortKeys, Limit: 10})
+	if sqlStr != "SELECT * FROM `user` WHERE (`name` = ?) ORDER BY `age` ASC, `id` ASC LIMIT 10" || conv.String(list) != `["a"]` {
+		t.Errorf("unexpected sql: %s %v", sqlStr, list)
+	}
+
+	cursor, err := sqlstatement.EncodeCursor(sortKeys, map[string]any{"id": int64(9007199254740993), "age": 18, "name": "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlStr, list = sta.KeysetSelectSql("user", allColumns, "", where, sqlstatement.KeysetPage{SortKeys: sortKeys, Cursor: cursor, Limit: 10})
+	if sqlStr != "SELECT * FROM `user` WHERE (`name` = ?) AND ((`age`, `id`) > (?, ?)) ORDER BY `age` ASC, `id` ASC LIMIT 10" ||
+		conv.String(list) != `["a",18,9007199254740993]` {
+		t.Errorf("unexpected sql: %s %v", sqlStr, list)
+	}
+
+	mixedKeys := []sqlstatement.OrderBy{sqlstatement.Desc("created_at"), sqlstatement.Asc("id")}
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	cursor, err = sqlstatement.EncodeCursor(mixedKeys, map[string]any{"id": 3, "created_at": createdAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := sqlstatement.DecodeCursor(cursor, mixedKeys)
+	if err != nil || len(values) != 2 || !values[0].(time.Time).Equal(createdAt) || values[1] != int64(3) {
+		t.Errorf("unexpected values: %v %v", values, err)
+	}
+	sqlStr, list = sta.KeysetSelectSql("user", allColumns, "id", sqlstatement.LogicCondition{}, sqlstatement.KeysetPage{SortKeys: mixedKeys, Cursor: cursor, Limit: 5})
+	if sqlStr != "SELECT `id` FROM `user` WHERE (`created_at` < ? OR (`created_at` = ? AND `id` > ?)) ORDER BY `created_at` DESC, `id` ASC LIMIT 5" ||
+		len(list) != 3 {
+		t.Errorf("unexpected sql: %s %v", sqlStr, list)
+	}
+
+	if _, err = sqlstatement.DecodeCursor(cursor, sortKeys); err == nil {
+		t.Error("expected error for mismatched sort keys")
+	}
+	if sqlStr, _ = sta.KeysetSelectSql("user", allColumns, "", where, sqlstatement.KeysetPage{SortKeys: sortKeys, Cursor: "bad"}); sqlStr != "" {
+		t.Errorf("unexpected sql: %s", sqlStr)
+	}
+	if sqlStr, _ = sta.KeysetSelectSql("user", allColumns, "", where, sqlstatement.KeysetPage{SortKeys: []sqlstatement.OrderBy{sqlstatement.Asc("password")}}); sqlStr != "" {
+		t.Errorf("unexpected sql: %s", sqlStr)
+	}
+	if _, err = sqlstatement.EncodeCursor(sortKeys, map[string]any{"id": nil, "age": 1}); err == nil {
+		t.Error("expected error for nil cursor value")
+	}
+}
+
+func TestLogicConditionJSON(t *testing.T) {
+	sta := new(sqlstatement.Statement)
+	allColumns := []string{"id", "name", "age", "status"}
+
+	data := []byte(`{"operator":"and","conditions":[
+		{"field":"age","operator":"between","value":[18,30]},
+		{"operator":"OR","conditions":[
+			{"field":"status","operator":"in","value":[1,2]},
+			{"field":"name","operator":"starts_with","value":"a_"}
+		]},
+		{"not":{"field":"id","value":9007199254740993}},
+		{"field":"name","operator":"!=","value":null}
+	]}`)
+	group, err := sqlstatement.ParseFilterJSON(data, allColumns...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlStr, list := sta.GenerateWhereClause(group)
+	expected := "(`age` BETWEEN ? AND ?) AND ((`status` IN (?,?)) OR (`name` LIKE ? ESCAPE '/')) AND " +
+		"(NOT (`id` = ?)) AND (`name` IS NOT NULL)"
+	if sqlStr != expected || conv.String(list) != `[18,30,1,2,"a/_%",9007199254740993]` {
+		t.Errorf("unexpected sql: %s %v", sqlStr, conv.String(list))
+	}
+
+	out, err := json.Marshal(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again := sqlstatement.LogicCondition{}
+	if err = json.Unmarshal(out, &again); err != nil {
+		t.Fatal(err)
+	}
+	if sqlAgain, listAgain := sta.GenerateWhereClause(again); sqlAgain != sqlStr || conv.String(listAgain) != conv.String(list) {
+		t.Errorf("unexpected sql after round trip: %s %s", sqlAgain, out)
+	}
+
+	out, err = json.Marshal(sqlstatement.LogicCondition{Conditions: []any{
+		sqlstatement.Condition{Field: "age", Operator: "between", Value: sqlstatement.Range{Start: 1, End: 2}},
+	}})
+	if err != nil || string(out) != `{"operator":"AND","conditions":[{"field":"age","operator":"BETWEEN","value":[1,2]}]}` {
+		t.Errorf("unexpected json: %s %v", out, err)
+	}
+
+	errCases := []string{
+		`{"conditions":[{"field":"password","value":1}]}`,
+		`{"conditions":[{"field":"age","operator":"=>","value":1}]}`,
+		`{"conditions":[{"field":"age; DROP TABLE user","value":1}]}`,
+		`{"operator":"XOR","conditions":[{"field":"age","value":1}]}`,
+		`{"conditions":[{"field":"age","value":{"a":1}}]}`,
+		`{"conditions":[{"field":"age","value":1,"extra":1}]}`,
+		`{"field":"age","value":1}`,
+	}
+	for _, one := range errCases {
+		if _, err = sqlstatement.ParseFilterJSON([]byte(one), allColumns...); err == nil {
+			t.Errorf("expected error for %s", one)
+		}
+	}
+
+	if _, err = json.Marshal(sqlstatement.LogicCondition{Conditions: []any{sqlstatement.NewExpr("1 = 1")}}); err == nil {
+		t.Error("expected error for expr")
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	sta := new(sqlstatement.Statement)
+	allColumns := []string{"id", "name", "age", "status", "deleted_at"}
+
+	group, err := sqlstatement.ParseFilter(`status IN (1,2) AND (name STARTS_WITH "ab" OR age >= 18)`, allColumns...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlStr, list := sta.GenerateWhereClause(group)
+	if sqlStr != "(`status` IN (?,?)) AND ((`name` LIKE ? ESCAPE '/') OR (`age` >= ?))" || conv.String(list) != `[1,2,"ab%",18]` {
+		t.Errorf("unexpected sql: %s %v", sqlStr, list)
+	}
+
+	text := `name not like binary 'a\'b' and not (age between -1 and 2.5 or deleted_at is not null) and id != 3 and status = true`
+	group, err = sqlstatement.ParseFilter(text, allColumns...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlStr, list = sta.GenerateWhereClause(group)
+	expected := "(`name` NOT LIKE BINARY ?) AND (NOT ((`age` BETWEEN ? AND ?) OR (`deleted_at` IS NOT NULL))) AND " +
+		"(`id` != ?) AND (`status` = ?)"
+	if sqlStr != expected || conv.String(list) != `["a'b",-1,2.5,3,true]` {
+		t.Errorf("unexpected sql: %s %v", sqlStr, list)
+	}
+
+	formatted, err := sqlstatement.FormatFilter(group)
+	expectedText := `name NOT LIKE BINARY "a'b" AND NOT (age BETWEEN -1 AND 2.5 OR deleted_at IS NOT NULL) AND id != 3 AND status = TRUE`
+	if err != nil || formatted != expectedText {
+		t.Errorf("unexpected text: %s %v", formatted, err)
+	}
+	again, err := sqlstatement.ParseFilter(formatted, allColumns...)
+	if sqlAgain, listAgain := sta.GenerateWhereClause(again); err != nil || sqlAgain != sqlStr || conv.String(listAgain) != conv.String(list) {
+		t.Errorf("unexpected sql after round trip: %s %v", sqlAgain, err)
+	}
+
+	formatted, err = sqlstatement.FormatFilter(sqlstatement.LogicCondition{Operator: "OR", Conditions: []any{
+		sqlstatement.Condition{Field: "name", Value: "a\"b\\c"},
+		sqlstatement.Condition{Field: "deleted_at", Value: nil},
+		sqlstatement.Condition{Field: "id", Value: []int{1, 2}},
+	}})
+	if err != nil || formatted != `name = "a\"b\\c" OR deleted_at IS NULL OR id = (1, 2)` {
+		t.Errorf("unexpected text: %s %v", formatted, err)
+	}
+
+	errCases := []struct {
+		text string
+		pos  int
+	}{
+		{text: `age >`, pos: 6},
+		{text: `age >= 18 AND`, pos: 14},
+		{text: `(age >= 18`, pos: 11},
+		{text: `age => 18`, pos: 5},
+		{text: `name = "abc`, pos: 8},
+		{text: `age >= 18 name = 1`, pos: 11},
+		{text: `age BETWEEN 1 OR 2`, pos: 15},
+		{text: `age IN (1 2)`, pos: 11},
+		{text: `age ; 1`, pos: 5},
+	}
+	for _, one := range errCases {
+		_, err = sqlstatement.ParseFilter(one.text, allColumns...)
+		var parseErr *sqlstatement.ParseError
+		if !errors.As(err, &parseErr) || parseErr.Pos != one.pos {
+			t.Errorf("unexpected error for %s: %v", one.text, err)
+		}
+	}
+
+	if _, err = sqlstatement.ParseFilter(`password = "1"`, allColumns...); err == nil {
+		t.Error("expected error for unknown column")
+	}
+}
+
+func TestColumnPolicy(t *testing.T) {
+	allColumns := []string{"id", "name", "age"}
+	where := sqlstatement.LogicCondition{
+		Conditions: []any{
+			sqlstatement.Condition{Field: "name", Operator: "=", Value: "a"},
+			sqlstatement.LogicCondition{Operator: "OR", Conditions: []any{
+				sqlstatement.Condition{Field: "user.age", Operator: ">", Value: 18},
+				sqlstatement.Not{Condition: sqlstatement.Condition{Field: "password", Operator: "=", Value: "p"}},
+			}},
+		},
+	}
+
+	sta := new(sqlstatement.Statement)
+	sqlStr, list := sta.SelectSqlByWhereCondition("user", allColumns, "", where, 0, 0)
+	if sqlStr != "SELECT * FROM `user` WHERE (`name` = ?) AND ((`user`.`age` > ?))" || conv.String(list) != `["a",18]` {
+		t.Errorf("unexpected sql: %s %v", sqlStr, list)
+	}
+	sqlStr, list = sta.UpdateSqlByWhereCondition("user", allColumns, map[string]any{"age": 1}, where)
+	if sqlStr != "UPDATE `user` SET `age`=? WHERE (`name` = ?) AND ((`user`.`age` > ?))" || conv.String(list) != `[1,"a",18]` {
+		t.Errorf("unexpected sql: %s %v", sqlStr, list)
+	}
+	sqlStr, _ = sta.DeleteSqlByWhereCondition("user", allColumns, sqlstatement.LogicCondition{
+		Conditions: []any{sqlstatement.Condition{Field: "password", Operator: "=", Value: "p"}},
+	})
+	if sqlStr != "" {
+		t.Errorf("expected refusing to delete all rows, got %s", sqlStr)
+	}
+	sqlStr, _ = sta.DeleteSql("user", allColumns, map[string]any{"password": "p"})
+	if sqlStr != "" {
+		t.Errorf("expected refusing to delete all rows, got %s", sqlStr)
+	}
+
+	reject := sqlstatement.NewStatement(sqlstatement.SetStatementColumnPolicy(sqlstatement.ColumnPolicyReject))
+	if sqlStr, _ = reject.SelectSqlByWhereCondition("user", allColumns, "", where, 0, 0); sqlStr != "" {
+		t.Errorf("unexpected sql: %s", sqlStr)
+	}
+	if sqlStr, _ = reject.SelectSql("user", allColumns, "", map[string]any{"name": "a", "password": "p"}, 0, 0); sqlStr != "" {
+		t.Errorf("unexpected sql: %s", sqlStr)
+	}
+	if sqlStr, _ = reject.UpdateSqlByWhereCondition("user", allColumns, map[string]any{"age": 1}, where); sqlStr != "" {
+		t.Errorf("unexpected sql: %s", sqlStr)
+	}
+
+	sqlObj := sqlstatement.NewSqlStruct(sqlstatement.SetStructData(&UserInfo{}), sqlstatement.SetColumnTagName("json"))
+	sqlStr, list, err := sqlObj.DeleteSql(where)
+	if err != nil || sqlStr != "DELETE FROM `user_info` WHERE (`name` = ?)" || conv.String(list) != `["a"]` {
+		t.Errorf("unexpected sql: %s %v %v", sqlStr, list, err)
+	}
+
+	sqlObj = sqlstatement.NewSqlStruct(sqlstatement.SetStructData(&UserInfo{}), sqlstatement.SetColumnTagName("json"),
+		sqlstatement.SetColumnPolicy(sqlstatement.ColumnPolicyReject))
+	if _, _, err = sqlObj.SelectSql("", where, 0, 0); err == nil {
+		t.Error("expected error for unknown field")
+	}
+	if _, _, err = sqlObj.UpdateSqlWithUpdateMap(map[string]any{"age": 1}, map[string]any{"password": "p"}); err == nil {
+		t.Error("expected error for unknown field")
+	}
+}
+
+func TestFullTableWrite(t *testing.T) {
+	allColumns := []string{"id", "name", "age"}
+
+	sta := new(sqlstatement.Statement)
+	if sqlStr, _ := sta.UpdateSql("user", allColumns, map[string]any{"age": 1}, nil); sqlStr != "" {
+		t.Errorf("unexpected sql: %s", sqlStr)
+	}
+	if sqlStr, _ := sta.DeleteSqlByWhereCondition("user", allColumns, sqlstatement.LogicCondition{}); sqlStr != "" {
+		t.Errorf("unexpected sql: %s", sqlStr)
+	}
+
+	if _, _, err := sta.UpdateSqlE("user", allColumns, map[string]any{"age": 1}, nil); !errors.Is(err, sqlstatement.ErrFullTableWrite) {
+		t.Errorf("expected ErrFullTableWrite, got %v", err)
+	}
+	if _, _, err := sta.UpdateSqlByWhereConditionE("user", allColumns, map[string]any{"age": 1}, sqlstatement.LogicCondition{}); !errors.Is(err, sqlstatement.ErrFullTableWrite) {
+		t.Errorf("expected ErrFullTableWrite, got %v", err)
+	}
+	if _, _, err := sta.DeleteSqlE("user", allColumns, map[string]any{"password": "p"}); !errors.Is(err, sqlstatement.ErrFullTableWrite) {
+		t.Errorf("expected ErrFullTableWrite, got %v", err)
+	}
+	if _, _, err := sta.DeleteSqlByWhereConditionE("user", allColumns, sqlstatement.LogicCondition{}); !errors.Is(err, sqlstatement.ErrFullTableWrite) {
+		t.Errorf("expected ErrFullTableWrite, got %v", err)
+	}
+	if _, _, err := sta.DeleteSqlE("user;drop", allColumns, map[string]any{"age": 1}); err == nil || errors.Is(err, sqlstatement.ErrFullTableWrite) {
+		t.Errorf("expected identifier error, got %v", err)
+	}
+
+	allow := sqlstatement.NewStatement(sqlstatement.SetStatementAllowFullTable(true))
+	if sqlStr, _ := allow.UpdateSql("user", allColumns, map[string]any{"age": 1}, nil); sqlStr != "UPDATE `user` SET `age`=?" {
+		t.Errorf("unexpected sql: %s", sqlStr)
+	}
+	if sqlStr, _ := allow.DeleteSql("user", allColumns, nil); sqlStr != "DELETE FROM `user`" {
+		t.Errorf("unexpected sql: %s", sqlStr)
+	}
+	if sqlStr, _ := allow.DeleteSql("user", allColumns, map[string]any{"password": "p"}); sqlStr != "" {
+		t.Errorf("expected refusing stripped conditions, got %s", sqlStr)
+	}
+
+	sqlObj := sqlstatement.NewSqlStruct(sqlstatement.SetStructData(&UserInfo{}), sqlstatement.SetColumnTagName("json"))
+	if _, _, err := sqlObj.DeleteSql(sqlstatement.LogicCondition{}); !errors.Is(err, sqlstatement.ErrFullTableWrite) {
+		t.Errorf("expected ErrFullTableWrite, got %v", err)
+	}
+	if _, _, err := sqlObj.UpdateSqlWithUpdateMap(map[string]any{"age": 1}, nil); !errors.Is(err, sqlstatement.ErrFullTableWrite) {
+		t.Errorf("expected ErrFullTableWrite, got %v", err)
+	}
+	if _, _, err := sqlObj.DeleteSqlByMap(map[string]any{"password": "p"}); !errors.Is(err, sqlstatement.ErrFullTableWrite) {
+		t.Errorf("expected ErrFullTableWrite, got %v", err)
+	}
+
+	sqlObj = sqlstatement.NewSqlStruct(sqlstatement.SetStructData(&UserInfo{}), sqlstatement.SetColumnTagName("json"),
+		sqlstatement.SetAllowFullTable(true))
+	sqlStr, _, err := sqlObj.UpdateSql(&UserInfo{Age: 1}, []string{"age"}, sqlstatement.LogicCondition{})
+	if err != nil || sqlStr != "UPDATE `user_info` SET `age` = ?" {
+		t.Errorf("unexpected sql: %s %v", sqlStr, err)
+	}
+}
+
+func TestDialect(t *testing.T) {
+	allColumns := []string{"id", "name", "cnt", "status"}
+	pg := sqlstatement.NewStatement(sqlstatement.SetStatementDialect(sqlstatement.DialectPostgreSQL))
+	sqlite := sqlstatement.NewStatement(sqlstatement.SetStatementDialect(sqlstatement.DialectSQLite))
+
+	where := sqlstatement.LogicCondition{
+		Conditions: []any{
+			sqlstatement.Condition{Field: "status", Operator: "IN", Value: []int{1, 2}},
+			sqlstatement.NewExpr("name != '?'"),
+			sqlstatement.Condition{Field: "name", Operator: "CONTAINS", Value: "a_"},
+		},
+	}
+	sqlStr, list := pg.SelectSqlByWhereCondition("user", allColumns, "id, name", where, 20, 10, sqlstatement.SetOrderBy(sqlstatement.Desc("id")))
+	expected := `SELECT "id", "name" FROM "user" WHERE ("status" IN ($1,$2)) AND (name != '?') AND ("name" LIKE $3 ESCAPE '/') ` +
+		`ORDER BY "id" DESC LIMIT 10 OFFSET 20`
+	if sqlStr != expected || conv.String(list) != `[1,2,"%a/_%"]` {
+		t.Errorf("unexpected sql: %s %v", sqlStr, list)
+	}
+
+	sqlStr, _ = pg.InsertSql("user", allColumns, map[string]any{"id": 1, "name": "a"})
+	if sqlStr != `INSERT INTO "user" ("id","name") VALUES ($1,$2)` {
+		t.Errorf("unexpected sql: %s", sqlStr)
+	}
+	sqlStr, _ = pg.UpdateSql("user", allColumns, map[string]any{"name": "a"}, map[string]any{"id": 1})
+	if sqlStr != `UPDATE "user" SET "name"=$1 WHERE ("id" = $2)` {
+		t.Errorf("unexpected sql: %s", sqlStr)
+	}
+
+	insertMap := map[string]any{"id": 1, "name": "a", "cnt": 1}
+	upsert := sqlstatement.Upsert{Increments: []string{"cnt"}, ConflictColumns: []string{"id"},
+		Exprs: map[string]sqlstatement.Expr{"status": sqlstatement.NewExpr("?", 2)}}
+	sqlStr, list = pg.UpsertSql("user", allColumns, insertMap, upsert)
+	expected = `INSERT INTO "user" ("cnt","id","name") VALUES ($1,$2,$3) ON CONFLICT ("id") DO UPDATE SET ` +
+		`"name" = EXCLUDED."name", "cnt" = "user"."cnt" + EXCLUDED."cnt", "status" = $4`
+	if sqlStr != expected || conv.String(list) != `[1,1,"a",2]` {
+		t.Errorf("unexpected sql: %s %v", sqlStr, list)
+	}
+	if sqlStr, _ = pg.UpsertSql("user", allColumns, insertMap, sqlstatement.Upsert{}); sqlStr != "" {
+		t.Errorf("expected error without conflict columns, got %s", sqlStr)
+	}
+	if sqlStr, _ = pg.UpsertSql("user", allColumns, insertMap, sqlstatement.Upsert{Mode: sqlstatement.UpsertReplace}); sqlStr != "" {
+		t.Errorf("expected error for replace, got %s", sqlStr)
+	}
+	sqlStr, _ = sqlite.UpsertSql("user", allColumns, insertMap, sqlstatement.Upsert{Mode: sqlstatement.UpsertIgnore})
+	if sqlStr != `INSERT INTO "user" ("cnt","id","name") VALUES (?,?,?) ON CONFLICT DO NOTHING` {
+		t.Errorf("unexpected sql: %s", sqlStr)
+	}
+	list2 := sqlite.BatchInsertSql("user", allColumns, []map[string]any{{"id": 1}, {"name": "a"}})
+	if len(list2) != 0 {
+		t.Errorf("expected error for DEFAULT in sqlite, got %s", conv.String(list2))
+	}
+
+	colon := sqlstatement.NewStatement(sqlstatement.SetStatementDialect(
+		sqlstatement.WithPlaceholder(sqlstatement.DialectSQLite, sqlstatement.PlaceholderColon)))
+	sqlStr, _ = colon.DeleteSql("user", allColumns, map[string]any{"id": 1, "name": "a"})
+	if sqlStr != `DELETE FROM "user" WHERE ("id" = :p1) AND ("name" = :p2)` {
+		t.Errorf("unexpected sql: %s", sqlStr)
+	}
+
+	sub := sqlstatement.NewSubQuery(pg.SelectSql("users", []string{"id", "age"}, "id", map[string]any{"age": 1}, 0, 0))
+	sqlStr, list = pg.SelectSqlByWhereCondition("t", []string{"x", "user_id"}, "", sqlstatement.LogicCondition{
+		Conditions: []any{
+			sqlstatement.Condition{Field: "x", Operator: "=", Value: 5},
+			sqlstatement.Condition{Field: "user_id", Operator: "IN", Value: sub},
+			sqlstatement.Condition{Field: "x", Operator: "!=", Value: 6},
+		},
+	}, 0, 0)
+	expected = `SELECT * FROM "t" WHERE ("x" = $1) AND ("user_id" IN (SELECT "id" FROM "users" WHERE ("age" = $2))) AND ("x" != $3)`
+	if sqlStr != expected || conv.String(list) != `[5,1,6]` {
+		t.Errorf("unexpected sql: %s %v", sqlStr, list)
+	}
+	sub = sqlstatement.NewSubQuery(colon.SelectSql("users", []string{"id", "age"}, "id", map[string]any{"age": 1}, 0, 0))
+	sqlStr, list = colon.DeleteSql("t", []string{"x", "user_id"}, map[string]any{"x": 5, "user_id": sub})
+	if sqlStr != `DELETE FROM "t" WHERE ("user_id" = (SELECT "id" FROM "users" WHERE ("age" = :p1))) AND ("x" = :p2)` ||
+		conv.String(list) != `[1,5]` {
+		t.Errorf("unexpected sql: %s %v", sqlStr, list)
+	}
+	if sqlStr, _ = pg.DeleteSql("t", []string{"user_id"}, map[string]any{"user_id": sqlstatement.NewSubQuery("SELECT 1 WHERE a = $2", []any{1})}); sqlStr != "" {
+		t.Errorf("unexpected sql: %s", sqlStr)
+	}
+
+	sqlObj := sqlstatement.NewSqlStruct(sqlstatement.SetStructData(&UserInfo{}), sqlstatement.SetColumnTagName("json"),
+		sqlstatement.SetDialect(sqlstatement.DialectPostgreSQL))
+	sqlStr, list, err := sqlObj.SelectSql("", sqlstatement.LogicCondition{
+		Conditions: []any{sqlstatement.Condition{Field: "age", Operator: ">", Value: 18}},
+	}, 0, 10)
+	if err != nil || sqlStr != `SELECT * FROM "user_info" WHERE ("age" > $1) LIMIT 10 OFFSET 0` || conv.String(list) != `[18]` {
+		t.Errorf("unexpected sql: %s %v %v", sqlStr, list, err)
+	}
+	sqlStr, _, err = sqlObj.UpdateSqlWithUpdateMap(map[string]any{"name": "a"}, map[string]any{"id": 1})
+	if err != nil || sqlStr != `UPDATE "user_info" SET "name"=$1 WHERE ("id" = $2)` {
+		t.Errorf("unexpected sql: %s %v", sqlStr, err)
+	}
+}
+
+func TestInterpolateForLog(t *testing.T) {
+	name := "a'b"
+	createTime := time.Date(2024, 1, 2, 3, 4, 5, 600000000, time.UTC)
+	testList := []struct {
+		sqlStr   string
+		args     []any
+		expected string
+		hasErr   bool
+	}{
+		{sqlStr: "SELECT * FROM `user` WHERE `name` = ? AND `id` IN (?,?)", args: []any{"x\\y\n\"z'", int64(-1), uint8(2)},
+			expected: "SELECT * FROM `user` WHERE `name` = 'x\\\\y\\n\\\"z\\'' AND `id` IN (-1,2)"},
+		{sqlStr: "UPDATE `user` SET `name`=?, `ok`=?, `score`=?, `data`=? WHERE (`note` = '?') AND `create_time` < ?",
+			args:     []any{&name, true, 1.5, []byte("ab"), createTime},
+			expected: "UPDATE `user` SET `name`='a\\'b', `ok`=TRUE, `score`=1.5, `data`=X'6162' WHERE (`note` = '?') AND `create_time` < '2024-01-02 03:04:05.6'"},
+		{sqlStr: "SELECT ? , ?", args: []any{nil, sql.NullInt64{Int64: 3, Valid: true}}, expected: "SELECT NULL , 3"},
+		{sqlStr: "SELECT ?", args: []any{}, hasErr: true},
+		{sqlStr: "SELECT ?", args: []any{1, 2}, hasErr: true},
+		{sqlStr: "SELECT ?", args: []any{map[string]any{"a": 1}}, hasErr: true},
+		{sqlStr: "SELECT ?", args: []any{math.NaN()}, hasErr: true},
+		{sqlStr: "SELECT ?", args: []any{"\xff"}, hasErr: true},
+	}
+	for _, one := range testList {
+		sqlStr, err := sqlstatement.InterpolateForLog(one.sqlStr, one.args...)
+		if (err != nil) != one.hasErr || sqlStr != one.expected {
+			t.Errorf("unexpected sql: %s %v, expected: %s", sqlStr, err, one.expected)
+		}
+	}
+
+	pg := sqlstatement.NewStatement(sqlstatement.SetStatementDialect(sqlstatement.DialectPostgreSQL))
+	sqlStr, args := pg.UpdateSql("user", []string{"id", "name", "ok", "data"}, map[string]any{"name": `a'\b`, "ok": true, "data": []byte{1}}, map[string]any{"id": 3})
+	out, err := sqlstatement.InterpolateForLogDialect(sqlstatement.DialectPostgreSQL, sqlStr, args...)
+	if err != nil || out != `UPDATE "user" SET "data"='\x01',"name"='a''\b',"ok"=TRUE WHERE ("id" = 3)` {
+		t.Errorf("unexpected sql: %s %v", out, err)
+	}
+	out, err = sqlstatement.InterpolateForLogDialect(sqlstatement.DialectSQLite, `SELECT 1 WHERE "a" = :p2 AND "b" = :p1`, false, "x")
+	if err != nil || out != `SELECT 1 WHERE "a" = 'x' AND "b" = 0` {
+		t.Errorf("unexpected sql: %s %v", out, err)
+	}
+}
+
+func TestTypedSqlStruct(t *testing.T) {
+	typed, err := sqlstatement.NewTypedSqlStruct[UserInfo](sqlstatement.SetColumnTagName("json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typed.TableName() != "`user_info`" || strings.Join(typed.Columns(), ",") != "age,id,name,nickname" {
+		t.Errorf("unexpected meta: %s %v", typed.TableName(), typed.Columns())
+	}
+	pgTyped, err := sqlstatement.NewTypedSqlStruct[UserInfo](sqlstatement.SetColumnTagName("json"),
+		sqlstatement.SetDialect(sqlstatement.DialectPostgreSQL))
+	if err != nil || pgTyped.TableName() != `"user_info"` {
+		t.Errorf("unexpected table name: %s %v", pgTyped.TableName(), err)
+	}
+	sqlStr, list, err := typed.InsertSql(&UserInfo{Id: 1, Name: "a"})
+	if err != nil || sqlStr != "INSERT INTO `user_info` (`age`,`id`,`name`) VALUES (?,?,?)" || conv.String(list) != `[0,1,"a"]` {
+		t.Errorf("unexpected sql: %s %v %v", sqlStr, list, err)
+	}
+	sqlStr, _, err = typed.SelectSqlByMap("", map[string]any{"name": "a"}, 0, 10)
+	if err != nil || sqlStr != "SELECT * FROM `user_info` WHERE (`name` = ?) LIMIT 0, 10" {
+		t.Errorf("unexpected sql: %s %v", sqlStr, err)
+	}
+	batchList, err := typed.BatchInsertSql([]*UserInfo{{Id: 1}, {Id: 2}})
+	if err != nil || len(batchList) != 1 {
+		t.Errorf("unexpected batch: %v %v", batchList, err)
+	}
+
+	if _, err = sqlstatement.NewTypedSqlStruct[int](); err == nil {
+		t.Errorf("expected error for non struct type")
+	}
+	if _, err = sqlstatement.NewTypedSqlStruct[UserInfo](sqlstatement.SetStructData(&AgeKey{})); err == nil {
+		t.Errorf("expected error for struct data type not match")
+	}
+
+	sqlObj := sqlstatement.NewSqlStruct(sqlstatement.SetStructData(&UserInfo{}), sqlstatement.SetColumnTagName("json"))
+	if _, _, err = sqlObj.InsertSql(&AgeKey{Age: 1}); err == nil {
+		t.Errorf("expected error for struct type not match")
+	}
+}
+
+type Article struct {
+	Id        int64     `xorm:"'id' pk autoincr"`
+	Title     string    `xorm:"varchar(64) notnull 'title' comment 'the title'"`
+	Views     int       `xorm:"<- views"`
+	Memo      string    `xorm:"-"`
+	CreatedAt time.Time `xorm:"created"`
+	UpdatedAt int64     `xorm:"bigint updated 'updated_at'"`
+	Version   int       `xorm:"version"`
+}
+
+func (Article) TableName() string {
+	return "t_article"
+}
+
+func TestXormTag(t *testing.T) {
+	sqlObj := sqlstatement.NewSqlStruct(sqlstatement.SetStructData(&Article{}), sqlstatement.SetColumnTagName("xorm"))
+	sqlStr, list, err := sqlObj.InsertSql(&Article{Title: "a", Views: 10, Memo: "m"})
+	if err != nil || sqlStr != "INSERT INTO `t_article` (`created_at`,`title`,`updated_at`,`version`) VALUES (?,?,?,?)" || len(list) != 4 {
+		t.Errorf("unexpected sql: %s %v %v", sqlStr, list, err)
+	} else if _, ok := list[0].(time.Time); !ok || list[1] != "a" || list[2].(int64) == 0 || list[3] != 1 {
+		t.Errorf("unexpected args: %v", list)
+	}
+
+	sqlStr, list, err = sqlObj.InsertSql(&Article{Id: 3, Title: "a", Version: 2})
+	if err != nil || !strings.Contains(sqlStr, "(`created_at`,`id`,`title`,`updated_at`,`version`)") || list[1] != int64(3) || list[4] != 2 {
+		t.Errorf("unexpected sql: %s %v %v", sqlStr, list, err)
+	}
+
+	sqlStr, list, err = sqlObj.UpdateSql(&Article{Id: 5, Title: "b", Views: 1, Version: 3}, nil, sqlstatement.LogicCondition{})
+	expected := "UPDATE `t_article` SET `title` = ?, `updated_at` = ?, `version` = ? WHERE (`id` = ?) AND (`version` = ?)"
+	if err != nil || sqlStr != expected || list[0] != "b" || list[2] != int64(4) || list[3] != int64(5) || list[4] != 3 {
+		t.Errorf("unexpected sql: %s %v %v", sqlStr, list, err)
+	}
+
+	sqlStr, list, err = sqlObj.UpdateSqlByMap(&Article{Id: 5, Title: "b"}, []string{"id", "title", "created_at"}, map[string]any{"title": "a"})
+	if err != nil || sqlStr != "UPDATE `t_article` SET `title`=?,`updated_at`=? WHERE (`title` = ?)" || list[0] != "b" || list[2] != "a" {
+		t.Errorf("unexpected sql: %s %v %v", sqlStr, list, err)
+	}
+
+	if _, _, err = sqlObj.UpdateSql(&Article{Title: "b"}, nil, sqlstatement.LogicCondition{}); !errors.Is(err, sqlstatement.ErrFullTableWrite) {
+		t.Errorf("expected full table error without pk: %v", err)
+	}
+
+	sqlStr, _, err = sqlObj.SelectSqlByMap("", map[string]any{"memo": "m", "views": 1}, 0, 0)
+	if err != nil || sqlStr != "SELECT * FROM `t_article` WHERE (`views` = ?)" {
+		t.Errorf("unexpected sql: %s %v", sqlStr, err)
+	}
+
+	typed, err := sqlstatement.NewTypedSqlStruct[Article](sqlstatement.SetColumnTagName("xorm"))
+	if err != nil || typed.TableName() != "`t_article`" || strings.Join(typed.Columns(), ",") != "created_at,id,title,updated_at,version,views" {
+		t.Errorf("unexpected meta: %v %v", typed, err)
+	}
+
+	type Follow struct {
+		Id     int64  `xorm:"pk autoincr BIGINT(20)"`
+		UserId int64  `xorm:"bigint notnull index(idx_uid) comment('user id')"`
+		Name   string `xorm:"varchar(32) unique(uq_name) default('') 'follow_name'"`
+		Remark string `xorm:"text null default 'a b' comment 'remark' extends"`
+	}
+	sqlObj = sqlstatement.NewSqlStruct(sqlstatement.SetStructData(&Follow{}), sqlstatement.SetColumnTagName("xorm"))
+	sqlStr, list, err = sqlObj.InsertSql(&Follow{UserId: 1, Name: "a", Remark: "r"})
+	if err != nil || sqlStr != "INSERT INTO `follow` (`follow_name`,`remark`,`user_id`) VALUES (?,?,?)" || conv.String(list) != `["a","r",1]` {
+		t.Errorf("unexpected sql: %s %v %v", sqlStr, list, err)
+	}
+}
+
+type Profile struct {
+	Id       int64   `json:"id" xorm:"pk"`
+	Name     string  `json:"name"`
+	Age      int     `json:"age"`
+	Remark   string  `json:"remark,omitempty"`
+	Nickname *string `json:"nickname"`
+}
+
+func TestZeroValuePolicy(t *testing.T) {
+	in := &Profile{Id: 1, Name: "a"}
+	tests := []struct {
+		policy  sqlstatement.ZeroValuePolicy
+		columns []string
+		insert  string
+		update  string
+	}{
+		{policy: sqlstatement.ZeroValueOmitNil, insert: "age,id,name", update: "age,name"},
+		{policy: sqlstatement.ZeroValueOmitZero, insert: "id,name", update: "name"},
+		{policy: sqlstatement.ZeroValueIncludeAll, insert: "age,id,name,nickname", update: "age,name,nickname"},
+		{policy: sqlstatement.ZeroValueOmitZero, columns: []string{"age", "remark", "nickname"}, update: "age,remark"},
+		{policy: sqlstatement.ZeroValueIncludeAll, columns: []string{"age", "nickname"}, update: "age,nickname"},
+	}
+	for _, one := range tests {
+		sqlObj := sqlstatement.NewSqlStruct(sqlstatement.SetStructData(&Profile{}), sqlstatement.SetColumnTagName("json"),
+			sqlstatement.SetZeroValuePolicy(one.policy))
+		if one.insert != "" {
+			columns, err := sqlObj.InsertColumns(in)
+			if err != nil || strings.Join(columns, ",") != one.insert {
+				t.Errorf("policy %d: unexpected insert columns: %v %v", one.policy, columns, err)
+			}
+		}
+		columns, err := sqlObj.UpdateColumns(in, one.columns)
+		if err != nil || strings.Join(columns, ",") != one.update {
+			t.Errorf("policy %d: unexpected update columns: %v %v", one.policy, columns, err)
+		}
+	}
+
+	sqlObj := sqlstatement.NewSqlStruct(sqlstatement.SetStructData(&Profile{}), sqlstatement.SetColumnTagName("json"),
+		sqlstatement.SetZeroValuePolicy(sqlstatement.ZeroValueOmitZero))
+	sqlStr, list, err := sqlObj.UpdateSql(in, nil, sqlstatement.LogicCondition{})
+	if err != nil || sqlStr != "UPDATE `profile` SET `name` = ? WHERE (`id` = ?)" || conv.String(list) != `["a",1]` {
+		t.Errorf("unexpected sql: %s %v %v", sqlStr, list, err)
+	}
+
+	_, columnMap, err := sqlstatement.StructToColumnsAndValues(in, "snake", "json")
+	if _, ok := columnMap["remark"]; err != nil || ok || len(columnMap) != 3 {
+		t.Errorf("unexpected columns: %v %v", columnMap, err)
+	}
+}
+
+func TestUpdateExpr(t *testing.T) {
+	sta := new(sqlstatement.Statement)
+	allColumns := []string{"id", "stock", "sold", "status", "remark", "updated_at"}
+	updateMap := map[string]any{
+		"stock":      sqlstatement.Decr(2),
+		"sold":       sqlstatement.Incr(2),
+		"status":     sqlstatement.Default(),
+		"remark":     sqlstatement.Null(),
+		"updated_at": sqlstatement.NewExpr("NOW()"),
+		"unknown":    sqlstatement.Incr(1),
+	}
+	sqlStr, list := sta.UpdateSql("goods", allColumns, updateMap, map[string]any{"id": 1})
+	expected := "UPDATE `goods` SET `remark`=NULL,`sold`=`sold` + ?,`status`=DEFAULT,`stock`=`stock` - ?,`updated_at`=NOW() WHERE (`id` = ?)"
+	if sqlStr != expected || conv.String(list) != `[2,2,1]` {
+		t.Errorf("unexpected sql: %s %v", sqlStr, list)
+	}
+
+	sqlStr, _ = sta.UpdateSql("goods", allColumns, map[string]any{"stock": sqlstatement.Incr(nil)}, map[string]any{"id": 1})
+	if sqlStr != "" {
+		t.Errorf("expected error for nil increment, got %s", sqlStr)
+	}
+	sqlStr, _ = sta.UpdateSql("goods", allColumns, map[string]any{"stock": sqlstatement.NewExpr("? + ?", 1)}, map[string]any{"id": 1})
+	if sqlStr != "" {
+		t.Errorf("expected error for invalid expr, got %s", sqlStr)
+	}
+
+	pg := sqlstatement.NewStatement(sqlstatement.SetStatementDialect(sqlstatement.DialectPostgreSQL))
+	sqlStr, list = pg.UpdateSql("goods", allColumns, map[string]any{"stock": sqlstatement.Decr(1), "status": 2}, map[string]any{"id": 1})
+	if sqlStr != `UPDATE "goods" SET "status"=$1,"stock"="stock" - $2 WHERE ("id" = $3)` || conv.String(list) != `[2,1,1]` {
+		t.Errorf("unexpected sql: %s %v", sqlStr, list)
+	}
+	sqlite := sqlstatement.NewStatement(sqlstatement.SetStatementDialect(sqlstatement.DialectSQLite))
+	if sqlStr, _ = sqlite.UpdateSql("goods", allColumns, map[string]any{"status": sqlstatement.Default()}, map[string]any{"id": 1}); sqlStr != "" {
+		t.Errorf("expected error for DEFAULT in sqlite, got %s", sqlStr)
+	}
+
+	sqlObj := sqlstatement.NewSqlStruct(sqlstatement.SetStructData(&UserInfo{}), sqlstatement.SetColumnTagName("json"))
+	sqlStr, list, err := sqlObj.UpdateSqlWithUpdateMap(map[string]any{"age": sqlstatement.Incr(1), "nickname": sqlstatement.Null()},
+		map[string]any{"id": 1})
+	if err != nil || sqlStr != "UPDATE `user_info` SET `age`=`age` + ?,`nickname`=NULL WHERE (`id` = ?)" || conv.String(list) != `[1,1]` {
+		t.Errorf("unexpected sql: %s %v %v", sqlStr, list, err)
+	}
+
+	type Score struct {
+		Id    int64 `json:"id"`
+		Score any   `json:"score"`
+	}
+	sqlObj = sqlstatement.NewSqlStruct(sqlstatement.SetStructData(&Score{}), sqlstatement.SetColumnTagName("json"))
+	sqlStr, list, err = sqlObj.UpdateSql(&Score{Score: sqlstatement.NewExpr("?", 3)}, []string{"score"}, sqlstatement.LogicCondition{
+		Conditions: []any{sqlstatement.Condition{Field: "id", Value: 1}},
+	})
+	if err != nil || sqlStr != "UPDATE `score` SET `score` = ? WHERE (`id` = ?)" || conv.String(list) != `[3,1]` {
+		t.Errorf("unexpected sql: %s %v %v", sqlStr, list, err)
+	}
+}
+
+func TestBulkUpdateSql(t *testing.T) {
+	sta := new(sqlstatement.Statement)
+	allColumns := []string{"id", "sort", "name", "stock"}
+	rows := []map[string]any{
+		{"id": 1, "sort": 3, "name": "a"},
+		{"id": 2, "sort": 1, "stock": sqlstatement.Decr(1)},
+		{"id": 3, "sort": 2, "unknown": 1},
+	}
+	list := sta.BulkUpdateSql("goods", allColumns, "id", rows)
+	expected := "UPDATE `goods` SET `name` = CASE `id` WHEN ? THEN ? ELSE `name` END, " +
+		"`sort` = CASE `id` WHEN ? THEN ? WHEN ? THEN ? WHEN ? THEN ? ELSE `sort` END, " +
+		"`stock` = CASE `id` WHEN ? THEN `stock` - ? ELSE `stock` END WHERE `id` IN (?,?,?)"
+	if len(list) != 1 || list[0].Sql != expected || conv.String(list[0].Args) != `[1,"a",1,3,2,1,3,2,2,1,1,2,3]` {
+		t.Errorf("unexpected sql: %s", conv.String(list))
+	}
+
+	list = sta.BulkUpdateSql("goods", allColumns, "id", rows, sqlstatement.SetMaxRows(2))
+	if len(list) != 2 || list[1].Sql != "UPDATE `goods` SET `sort` = CASE `id` WHEN ? THEN ? ELSE `sort` END WHERE `id` IN (?)" ||
+		conv.String(list[1].Args) != `[3,2,3]` {
+		t.Errorf("unexpected sql: %s", conv.String(list))
+	}
+	list = sta.BulkUpdateSql("goods", allColumns, "id", rows, sqlstatement.SetMaxPlaceholders(11))
+	if len(list) != 2 {
+		t.Errorf("unexpected sql: %s", conv.String(list))
+	}
+
+	errRows := [][]map[string]any{
+		{},
+		{{"id": 1, "sort": 1}, {"sort": 2}},
+		{{"id": 1, "sort": 1}, {"id": 1, "sort": 2}},
+		{{"id": 1}},
+		{{"id": 1, "sort": sqlstatement.Default()}},
+	}
+	for _, one := range errRows {
+		if list = sta.BulkUpdateSql("goods", allColumns, "id", one); len(list) != 0 {
+			t.Errorf("expected error for rows %v, got %s", one, conv.String(list))
+		}
+	}
+	if list = sta.BulkUpdateSql("goods", allColumns, "uid", rows); len(list) != 0 {
+		t.Errorf("expected error for unknown key column, got %s", conv.String(list))
+	}
+	if _, err := sta.BulkUpdateSqlE("goods", allColumns, "id", errRows[2]); err == nil || !strings.Contains(err.Error(), "duplicate key") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	pg := sqlstatement.NewStatement(sqlstatement.SetStatementDialect(sqlstatement.DialectPostgreSQL))
+	list = pg.BulkUpdateSql("goods", allColumns, "id", []map[string]any{{"id": 1, "sort": 2}, {"id": 2, "sort": 1}})
+	if len(list) != 1 || list[0].Sql != `UPDATE "goods" SET "sort" = CASE "id" WHEN $1 THEN $2 WHEN $3 THEN $4 ELSE "sort" END WHERE "id" IN ($5,$6)` {
+		t.Errorf("unexpected sql: %s", conv.String(list))
+	}
+}
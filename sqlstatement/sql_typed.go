@@ -0,0 +1,119 @@
+package sqlstatement
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// TypedSqlStruct 绑定一个结构体类型的 SqlStruct，参数类型在编译时检查，表名和字段在创建时解析一次
+type TypedSqlStruct[T any] struct {
+	s *SqlStruct
+}
+
+// NewTypedSqlStruct 新建一个对象，T 必须为结构体类型，SetStructData 设置的值必须为 T 或 *T
+func NewTypedSqlStruct[T any](opts ...Option) (*TypedSqlStruct[T], error) {
+	s := NewSqlStruct(opts...)
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("typed sql struct must be struct: %s", t)
+	}
+	if s.structData != nil && structType(s.structData) != t {
+		return nil, fmt.Errorf("struct type not match: %s, %T", t, s.structData)
+	}
+	s.structData = new(T)
+
+	tableName, _, err := s.commGetTableNameAndColumns(s.structData)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := s.commGetAllColumns(s.structData)
+	if err != nil {
+		return nil, err
+	}
+	fields, _, err := s.commGetStructFields(s.structData)
+	if err != nil {
+		return nil, err
+	}
+	s.meta = &structMeta{tableName: tableName, columns: columns, fields: fields}
+	return &TypedSqlStruct[T]{s: s}, nil
+}
+
+// TableName 按设置的方言转义的表名
+func (t *TypedSqlStruct[T]) TableName() string {
+	return rebindSql(t.s.statement().getDialect(), t.s.meta.tableName)
+}
+
+// Columns 结构体对应的所有字段名
+func (t *TypedSqlStruct[T]) Columns() []string {
+	return append([]string{}, t.s.meta.columns...)
+}
+
+// InsertSql 插入的sql语句
+func (t *TypedSqlStruct[T]) InsertSql(in *T) (string, []any, error) {
+	return t.s.InsertSql(in)
+}
+
+// InsertColumns InsertSql 实际插入的字段
+func (t *TypedSqlStruct[T]) InsertColumns(in *T) ([]string, error) {
+	return t.s.InsertColumns(in)
+}
+
+// InsertSqlByMap 插入的sql语句
+func (t *TypedSqlStruct[T]) InsertSqlByMap(inMap map[string]any) (string, []any, error) {
+	return t.s.InsertSqlByMap(inMap)
+}
+
+// BatchInsertSql 批量插入的sql语句，超过限制时会拆分为多条语句
+func (t *TypedSqlStruct[T]) BatchInsertSql(rows []*T, opts ...BatchOption) ([]BatchSql, error) {
+	return t.s.BatchInsertSql(rows, opts...)
+}
+
+// UpsertSql 插入或更新的sql语句，冲突处理方式见 Upsert
+func (t *TypedSqlStruct[T]) UpsertSql(in *T, upsert Upsert) (string, []any, error) {
+	return t.s.UpsertSql(in, upsert)
+}
+
+// BatchUpsertSql 批量插入或更新的sql语句，超过限制时会拆分为多条语句
+func (t *TypedSqlStruct[T]) BatchUpsertSql(rows []*T, upsert Upsert, opts ...BatchOption) ([]BatchSql, error) {
+	return t.s.BatchUpsertSql(rows, upsert, opts...)
+}
+
+// DeleteSql 删除的sql语句
+func (t *TypedSqlStruct[T]) DeleteSql(whereCondition LogicCondition) (string, []any, error) {
+	return t.s.DeleteSql(whereCondition)
+}
+
+// DeleteSqlByMap 删除的sql语句，map里的关系是And关系
+func (t *TypedSqlStruct[T]) DeleteSqlByMap(whereMap map[string]any) (string, []any, error) {
+	return t.s.DeleteSqlByMap(whereMap)
+}
+
+// UpdateSql 修改的sql语句
+func (t *TypedSqlStruct[T]) UpdateSql(in *T, columns []string, whereCondition LogicCondition) (string, []any, error) {
+	return t.s.UpdateSql(in, columns, whereCondition)
+}
+
+// UpdateColumns UpdateSql 实际更新的字段
+func (t *TypedSqlStruct[T]) UpdateColumns(in *T, columns []string) ([]string, error) {
+	return t.s.UpdateColumns(in, columns)
+}
+
+// UpdateSqlByMap 修改的sql语句
+func (t *TypedSqlStruct[T]) UpdateSqlByMap(in *T, columns []string, whereMap map[string]any) (string, []any, error) {
+	return t.s.UpdateSqlByMap(in, columns, whereMap)
+}
+
+// UpdateSqlWithUpdateMap 更新的sql语句，map里的关系是And关系
+func (t *TypedSqlStruct[T]) UpdateSqlWithUpdateMap(updateMap map[string]any, whereMap map[string]any) (string, []any, error) {
+	return t.s.UpdateSqlWithUpdateMap(updateMap, whereMap)
+}
+
+// SelectSql 查询的sql语句
+func (t *TypedSqlStruct[T]) SelectSql(selectStr string, whereCondition LogicCondition, offset, limit int, opts ...SelectOption) (string, []any, error) {
+	return t.s.SelectSql(selectStr, whereCondition, offset, limit, opts...)
+}
+
+// SelectSqlByMap 查询的sql语句
+func (t *TypedSqlStruct[T]) SelectSqlByMap(selectStr string, whereMap map[string]any, offset, limit int, opts ...SelectOption) (string, []any, error) {
+	return t.s.SelectSqlByMap(selectStr, whereMap, offset, limit, opts...)
+}
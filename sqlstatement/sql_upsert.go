@@ -0,0 +1,126 @@
+package sqlstatement
+
+import (
+	"fmt"
+	"github.com/samber/lo"
+	"sort"
+)
+
+// UpsertMode 插入冲突时的处理方式
+type UpsertMode string
+
+const (
+	UpsertUpdate  UpsertMode = ""        // INSERT ... ON DUPLICATE KEY UPDATE
+	UpsertIgnore  UpsertMode = "IGNORE"  // INSERT IGNORE，冲突时忽略
+	UpsertReplace UpsertMode = "REPLACE" // REPLACE INTO，冲突时删除旧行再插入
+)
+
+// Upsert 插入冲突时的处理
+type Upsert struct {
+	Mode            UpsertMode
+	UpdateColumns   []string        // 冲突时更新为插入值的列，为空时更新所有插入的列（除 ConflictColumns、Increments 和 Exprs 中的列）
+	Increments      []string        // 冲突时累加插入值的列，如 cnt = cnt + VALUES(cnt)
+	Exprs           map[string]Expr // 冲突时使用表达式更新的列，如 updated_at = NOW()
+	RowAlias        string          // 使用 mysql 8.0.19 以上的行别名写法，如 AS new ... col = new.col
+	ConflictColumns []string        // 冲突判断的唯一键字段，PostgreSQL 和 SQLite 更新时必须设置
+}
+
+// apply 通过方言设置插入语句的动词和冲突时的语句
+// columns 为本次插入的列，更新插入值的列必须在其中
+func (u *Upsert) apply(ins *batchInsert, d Dialect, tableName string, allColumns []string, columns []string) error {
+	st := new(Statement)
+	clause := UpsertClause{
+		Mode:            u.Mode,
+		Table:           st.buildOneFieldName(tableName),
+		ConflictColumns: st.buildFieldNames(u.ConflictColumns),
+		RowAlias:        st.buildOneFieldName(u.RowAlias),
+	}
+	args := make([]any, 0)
+	if u.Mode == UpsertUpdate {
+		var err error
+		if clause.Assignments, args, err = u.assignments(allColumns, columns); err != nil {
+			return err
+		}
+	}
+	verb, suffix, err := d.Upsert(clause)
+	if err != nil {
+		return err
+	}
+	ins.verb, ins.suffix, ins.suffixArgs = verb, suffix, args
+	return nil
+}
+
+// assignments 冲突时更新的字段和表达式的参数
+func (u *Upsert) assignments(allColumns []string, columns []string) ([]UpsertAssignment, []any, error) {
+	st := new(Statement)
+	allColumns = st.buildFieldNames(allColumns)
+	increments := st.buildFieldNames(u.Increments)
+	exprColumns := lo.Keys(u.Exprs)
+	sort.Strings(exprColumns)
+
+	updateColumns := st.buildFieldNames(u.UpdateColumns)
+	if len(updateColumns) == 0 {
+		conflictColumns := st.buildFieldNames(u.ConflictColumns)
+		updateColumns = lo.Filter(columns, func(item string, index int) bool {
+			return lo.IndexOf(increments, item) < 0 && u.Exprs[item].Sql == "" && lo.IndexOf(conflictColumns, item) < 0
+		})
+	}
+
+	list := make([]UpsertAssignment, 0)
+	args := make([]any, 0)
+	for _, column := range updateColumns {
+		if lo.IndexOf(columns, column) < 0 {
+			return nil, nil, fmt.Errorf("update column not in insert columns: %s", column)
+		}
+		list = append(list, UpsertAssignment{Column: column})
+	}
+	for _, column := range increments {
+		if lo.IndexOf(columns, column) < 0 {
+			return nil, nil, fmt.Errorf("increment column not in insert columns: %s", column)
+		}
+		list = append(list, UpsertAssignment{Column: column, Increment: true})
+	}
+	for _, column := range exprColumns {
+		name := st.buildOneFieldName(column)
+		if lo.IndexOf(allColumns, name) < 0 {
+			return nil, nil, fmt.Errorf("expr column not in columns: %s", column)
+		}
+		exprSql, exprArgs, err := u.Exprs[column].build()
+		if err != nil {
+			return nil, nil, err
+		}
+		list = append(list, UpsertAssignment{Column: name, Expr: exprSql})
+		args = append(args, exprArgs...)
+	}
+	return list, args, nil
+}
+
+// UpsertSql 插入或更新的sql语句，冲突处理方式见 Upsert
+func (s *Statement) UpsertSql(tableName string, allColumns []string, insertMap map[string]any, upsert Upsert) (string, []any) {
+	sqlStr, args, err := s.UpsertSqlE(tableName, allColumns, insertMap, upsert)
+	if err != nil {
+		return "", []any{}
+	}
+	return sqlStr, args
+}
+
+// UpsertSqlE 与 UpsertSql 相同，不能生成时返回原因
+func (s *Statement) UpsertSqlE(tableName string, allColumns []string, insertMap map[string]any, upsert Upsert) (string, []any, error) {
+	list, err := s.batchInsertSql(tableName, allColumns, []map[string]any{insertMap}, &upsert)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(list) == 0 {
+		return "", nil, fmt.Errorf("upsert sql is empty")
+	}
+	return list[0].Sql, list[0].Args, nil
+}
+
+// BatchUpsertSql 批量插入或更新的sql语句，超过限制时会拆分为多条语句
+func (s *Statement) BatchUpsertSql(tableName string, allColumns []string, rows []map[string]any, upsert Upsert, opts ...BatchOption) []BatchSql {
+	list, err := s.batchInsertSql(tableName, allColumns, rows, &upsert, opts...)
+	if err != nil {
+		return []BatchSql{}
+	}
+	return list
+}
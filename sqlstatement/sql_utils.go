@@ -1,42 +1,61 @@
 package sqlstatement
 
 import (
+	"fmt"
+	"github.com/samber/lo"
 	"github.com/tianlin0/go-plat-utils/utils"
 	"reflect"
+	"sort"
 	"strings"
 )
 
 // StructToColumnsAndValues 将结构体转换为 SQL 对应的列名列表和值列表
 // convertType 默认的转换方式，如果没有获取到tag，则默认的转换方式。
 // 支持的类型有：snake 蛇形命名，camel 驼峰命名，lower 小写命名, upper 大写命名
+// 结构体实现了 TableName() 时使用其返回值作为表名，xorm 标签为 - 的字段会被忽略，omitempty 的字段为零值时会被忽略
 func StructToColumnsAndValues(in any, convertType string, tagNames ...string) (tableName string, columnsMap map[string]any, err error) {
-	structName, columnMap, err := utils.GetStructInfoByTag(in, func(s string) string {
-		return convertToByType(s, convertType)
-	}, tagNames...)
-
+	v, err := structValue(in)
 	if err != nil {
 		return "", nil, err
 	}
+	info := parseStructFields(v.Type(), convertType, tagNames...)
+	tableName = info.getTableName(v, convertType)
 
-	tableName = convertToByType(structName, convertType)
+	//需要过滤出nil的项目，以及标签为 omitempty 的零值
+	columnsMap = structColumnMap(v, info, ZeroValueOmitNil)
 
-	columnsMap = make(map[string]any)
-	//需要过滤出nil的项目
-	for key, value := range columnMap {
-		if value == nil {
-			continue
-		}
-		ti := reflect.TypeOf(value)
-		if ti.Kind() == reflect.Ptr {
-			vi := reflect.ValueOf(value)
-			if vi.IsNil() {
-				continue
-			}
-		}
-		columnsMap[key] = value
+	return tableName, columnsMap, nil
+}
+
+// structColumnNames 获取结构体对应的所有列名，包括值为nil的字段
+func structColumnNames(in any, convertType string, tagNames ...string) ([]string, error) {
+	v, err := structValue(in)
+	if err != nil {
+		return nil, err
+	}
+	info := parseStructFields(v.Type(), convertType, tagNames...)
+	columns := make([]string, 0, len(info.fields))
+	for _, field := range info.fields {
+		columns = append(columns, field.column)
 	}
+	columns = lo.Uniq(columns)
+	sort.Strings(columns)
+	return columns, nil
+}
 
-	return tableName, columnsMap, nil
+// structValue 获取结构体的值，in 可以为结构体或结构体指针
+func structValue(in any) (reflect.Value, error) {
+	v := reflect.ValueOf(in)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}, fmt.Errorf("input is a nil pointer")
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("input must be struct: %T", in)
+	}
+	return v, nil
 }
 
 func convertToByType(in string, convertType string) string {
@@ -55,27 +74,43 @@ func convertToByType(in string, convertType string) string {
 	}
 	return in
 }
-func getSliceByMap(columnsMap map[string]any) ([]string, []any) {
-	columns := make([]string, 0)
-	dataList := make([]any, 0)
 
-	for k, v := range columnsMap {
+// getSliceByMap 将map转为按key排序的列名和值，保证生成的sql稳定
+func getSliceByMap(columnsMap map[string]any) ([]string, []any) {
+	columns := make([]string, 0, len(columnsMap))
+	for k := range columnsMap {
 		columns = append(columns, k)
-		dataList = append(dataList, v)
+	}
+	sort.Strings(columns)
+
+	dataList := make([]any, 0, len(columns))
+	for _, k := range columns {
+		dataList = append(dataList, columnsMap[k])
 	}
 	return columns, dataList
 }
 
 // addCodeForColumns 为column添加`符号，避免冲突
-func addCodeForColumns(columns []string) []string {
-	newColumns := make([]string, 0)
+func addCodeForColumns(columns []string) ([]string, error) {
+	newColumns := make([]string, 0, len(columns))
 	for _, column := range columns {
-		column = strings.ReplaceAll(column, "`", "")
-		newColumns = append(newColumns, "`"+column+"`")
+		quoted, err := QuoteIdentifier(column)
+		if err != nil {
+			return nil, err
+		}
+		newColumns = append(newColumns, quoted)
 	}
-	return newColumns
+	return newColumns, nil
+}
+func addCodeForOneColumn(column string) (string, error) {
+	return QuoteIdentifier(column)
 }
-func addCodeForOneColumn(column string) string {
-	column = strings.ReplaceAll(column, "`", "")
-	return "`" + column + "`"
+
+// isNilValue 判断是否为nil，包括值为nil的指针
+func isNilValue(value any) bool {
+	if value == nil {
+		return true
+	}
+	vi := reflect.ValueOf(value)
+	return vi.Kind() == reflect.Ptr && vi.IsNil()
 }
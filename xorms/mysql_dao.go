@@ -141,8 +141,20 @@ func (m *Dao) Get(id any, info any) (bool, error) {
 	return m.engine.ID(id).Get(info)
 }
 
-// UpdateWhere 条件更新
+// UpdateWhere 条件更新，whereStr 为空时返回 sqlstatement.ErrFullTableWrite，更新全表请使用 UpdateAll
 func (m *Dao) UpdateWhere(whereStr string, argList []any, info any, columns ...string) (int64, error) {
+	if strings.TrimSpace(whereStr) == "" {
+		return 0, sqlstatement.ErrFullTableWrite
+	}
+	return m.update(whereStr, argList, info, columns...)
+}
+
+// UpdateAll 更新全表，需要显式调用
+func (m *Dao) UpdateAll(info any, columns ...string) (int64, error) {
+	return m.update("1 = 1", nil, info, columns...)
+}
+
+func (m *Dao) update(whereStr string, argList []any, info any, columns ...string) (int64, error) {
 	if m.daoSession != nil {
 		sessionIns := m.daoSession.Where(whereStr, argList...)
 		if len(columns) > 0 {
@@ -157,8 +169,20 @@ func (m *Dao) UpdateWhere(whereStr string, argList []any, info any, columns ...s
 	return sessionIns.Update(info)
 }
 
-// DeleteWhere 条件删除
+// DeleteWhere 条件删除，whereStr 为空时返回 sqlstatement.ErrFullTableWrite，删除全表请使用 DeleteAll
 func (m *Dao) DeleteWhere(whereStr string, argList []any, info any) (int64, error) {
+	if strings.TrimSpace(whereStr) == "" {
+		return 0, sqlstatement.ErrFullTableWrite
+	}
+	return m.delete(whereStr, argList, info)
+}
+
+// DeleteAll 删除全表，需要显式调用
+func (m *Dao) DeleteAll(info any) (int64, error) {
+	return m.delete("1 = 1", nil, info)
+}
+
+func (m *Dao) delete(whereStr string, argList []any, info any) (int64, error) {
 	if m.daoSession != nil {
 		return m.daoSession.Where(whereStr, argList...).Unscoped().Delete(info)
 	}
@@ -181,7 +205,7 @@ func (m *Dao) TransAction(callback TransCallback) error {
 	}(session)
 
 	if err := session.Begin(); err != nil {
-		return fmt.Errorf("fail to session begin：" + err.Error())
+		return fmt.Errorf("fail to session begin：%s", err.Error())
 	}
 
 	m.daoSessionLock.Lock()
@@ -340,21 +364,22 @@ func (m *Dao) SqlQuery(sqlStr string, args ...any) ([]map[string]string, error)
 	return retData, nil
 }
 
-// SqlExec sql更新
-func (m *Dao) SqlExec(sqlStr string, args ...any) (int64, error) {
+// exec 执行sql，有事务时在事务中执行
+func (m *Dao) exec(sqlStr string, args ...any) (sql.Result, error) {
 	queryParam := make([]any, 0)
 	queryParam = append(queryParam, sqlStr)
 	if args != nil && len(args) > 0 {
 		queryParam = append(queryParam, args...)
 	}
-	var execResult sql.Result
-	var err error
 	if m.daoSession != nil {
-		execResult, err = m.daoSession.Exec(queryParam...)
-	} else {
-		execResult, err = m.engine.Exec(queryParam...)
+		return m.daoSession.Exec(queryParam...)
 	}
+	return m.engine.Exec(queryParam...)
+}
 
+// SqlExec sql更新
+func (m *Dao) SqlExec(sqlStr string, args ...any) (int64, error) {
+	execResult, err := m.exec(sqlStr, args...)
 	if err != nil {
 		return 0, err
 	}
@@ -371,3 +396,93 @@ func (m *Dao) SqlExec(sqlStr string, args ...any) (int64, error) {
 	}
 	return num, nil
 }
+
+// UpsertAction 插入或更新单行的结果
+type UpsertAction int
+
+const (
+	UpsertUnchanged UpsertAction = iota // 已存在且没有变化，或 INSERT IGNORE 被忽略
+	UpsertInserted                      // 新插入
+	UpsertUpdated                       // 已存在并更新，REPLACE 时表示替换了旧行
+)
+
+// getUpsertAction 根据mysql影响的行数判断结果：1 插入，2 更新，0 未变化
+// 注意：连接参数设置了 clientFoundRows=true 时，未变化的行也会返回1
+func getUpsertAction(affected int64) UpsertAction {
+	switch {
+	case affected <= 0:
+		return UpsertUnchanged
+	case affected == 1:
+		return UpsertInserted
+	}
+	return UpsertUpdated
+}
+
+// Upsert 执行单行的 INSERT ... ON DUPLICATE KEY UPDATE / INSERT IGNORE / REPLACE 语句，返回插入还是更新
+func (m *Dao) Upsert(sqlStr string, args ...any) (UpsertAction, error) {
+	if sqlStr == "" {
+		return UpsertUnchanged, fmt.Errorf("upsert sql is empty")
+	}
+	execResult, err := m.exec(sqlStr, args...)
+	if err != nil {
+		return UpsertUnchanged, err
+	}
+	affected, err := execResult.RowsAffected()
+	if err != nil {
+		return UpsertUnchanged, err
+	}
+	return getUpsertAction(affected), nil
+}
+
+// UpsertRows 逐行插入或更新，返回每一行的结果，需要原子性时请在 TransAction 中调用
+func (m *Dao) UpsertRows(tableName string, allColumns []string, rows []map[string]any, upsert sqlstatement.Upsert) ([]UpsertAction, error) {
+	st := new(sqlstatement.Statement)
+	retList := make([]UpsertAction, 0, len(rows))
+	for i, row := range rows {
+		sqlStr, args, err := st.UpsertSqlE(tableName, allColumns, row, upsert)
+		if err != nil {
+			return retList, fmt.Errorf("upsert row %d: %w", i, err)
+		}
+		action, err := m.Upsert(sqlStr, args...)
+		if err != nil {
+			return retList, err
+		}
+		retList = append(retList, action)
+	}
+	return retList, nil
+}
+
+// BulkUpdate 按 keyColumn 将多行分别更新为各自的值，返回影响的行数
+// 拆分为多条语句且不在事务中时，会在同一个事务中执行
+func (m *Dao) BulkUpdate(tableName string, allColumns []string, keyColumn string, rows []map[string]any, opts ...sqlstatement.BatchOption) (int64, error) {
+	list, err := new(sqlstatement.Statement).BulkUpdateSqlE(tableName, allColumns, keyColumn, rows, opts...)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	execAll := func() error {
+		for _, one := range list {
+			execResult, err := m.exec(one.Sql, one.Args...)
+			if err != nil {
+				return err
+			}
+			num, err := execResult.RowsAffected()
+			if err != nil {
+				return err
+			}
+			total += num
+		}
+		return nil
+	}
+	if len(list) == 1 || m.daoSession != nil {
+		err = execAll()
+		return total, err
+	}
+	err = m.TransAction(func(*xorm.Session) error {
+		return execAll()
+	})
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
@@ -2,7 +2,7 @@ package xorms
 
 var (
 	explainSql         = false                                       //执行分析索引命中的情况
-	operatorList       = []string{"LIKE", "=", ">=", ">", "<=", "<"} // 数据库支持的类型
+	interpolateSql     = false                                       //日志中的sql是否替换为参数值
 	likeUseReplaceList = []string{"%", "_"}                          //like需要替换的字符
 	likeUseEscapeList  = []string{"/", "&", "#", "@", "^", "$", "!"} //定义可以使用的escape列表
 )
@@ -11,3 +11,8 @@ var (
 func SetExplainSql(explain bool) {
 	explainSql = explain
 }
+
+// SetInterpolateSqlLog 设置日志中的sql是否将参数替换到占位符中，仅方便阅读，无法替换时仍输出原始sql和参数
+func SetInterpolateSqlLog(interpolate bool) {
+	interpolateSql = interpolate
+}
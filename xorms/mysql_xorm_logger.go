@@ -2,6 +2,7 @@ package xorms
 
 import (
 	"fmt"
+	"github.com/tianlin0/go-plat-mysql/sqlstatement"
 	"github.com/tianlin0/go-plat-utils/logs"
 	xormlog "xorm.io/xorm/log"
 )
@@ -81,6 +82,30 @@ func (x *xormLogger) Warnf(format string, v ...any) {
 	x.Warn(fmt.Sprintf(format, v...))
 }
 
+// BeforeSQL 执行sql前
+func (x *xormLogger) BeforeSQL(xormlog.LogContext) {}
+
+// AfterSQL 执行sql后输出sql，与 xorm 默认的格式一致
+func (x *xormLogger) AfterSQL(ctx xormlog.LogContext) {
+	var sessionPart string
+	if ctx.Ctx != nil {
+		if key, ok := ctx.Ctx.Value(xormlog.SessionIDKey).(string); ok {
+			sessionPart = fmt.Sprintf(" [%s]", key)
+		}
+	}
+	sqlStr := fmt.Sprintf("%s %v", ctx.SQL, ctx.Args)
+	if interpolateSql {
+		if interpolated, err := sqlstatement.InterpolateForLog(ctx.SQL, ctx.Args...); err == nil {
+			sqlStr = "(interpolated, not for execution) " + interpolated
+		}
+	}
+	if ctx.ExecuteTime > 0 {
+		x.Infof("[SQL]%s %s - %v", sessionPart, sqlStr, ctx.ExecuteTime)
+	} else {
+		x.Infof("[SQL]%s %s", sessionPart, sqlStr)
+	}
+}
+
 // Level 等级
 func (x *xormLogger) Level() xormlog.LogLevel {
 	level := x.commLog.Level()